@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/joac1144/bootdev-chirpy/internal/auth"
+	"github.com/joac1144/bootdev-chirpy/internal/database"
+)
+
+const UpdateUserRolePath string = "PUT /admin/users/{userId}/role"
+
+// RequireRole only lets requests through to next if their access token
+// carries at least the given role. The claims are passed on in the request
+// context, so nested checks don't validate the token again.
+func (config *ApiConfig) RequireRole(role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		claims, err := config.claims(req)
+		if err != nil {
+			respondUnauthorized(rw, err)
+			return
+		}
+		if !auth.HasRole(claims.Role, role) {
+			respondError(rw, http.StatusForbidden, "Forbidden")
+			return
+		}
+		next(rw, req.WithContext(context.WithValue(req.Context(), claimsKey{}, claims)))
+	}
+}
+
+// UpdateUserRoleHandler changes the role of a user. Their access tokens are
+// revoked when the role changes, so the old role stops working right away and
+// the new one is part of the tokens from their next refresh.
+func (config *ApiConfig) UpdateUserRoleHandler(rw http.ResponseWriter, req *http.Request) {
+	userId, err := uuid.Parse(req.PathValue("userId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	type reqData struct {
+		Role string `json:"role"`
+	}
+	decoder := json.NewDecoder(req.Body)
+	params := reqData{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if !auth.IsValidRole(params.Role) {
+		respondError(rw, http.StatusBadRequest, "Role must be 'user', 'moderator' or 'admin'")
+		return
+	}
+
+	user, err := config.Db.UpdateUserRole(req.Context(), database.UpdateUserRoleParams{
+		Role: params.Role,
+		ID:   userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(rw, http.StatusNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	config.tokenRevocations.forget(userId)
+
+	respond(rw, http.StatusOK, mapUser(user))
+}
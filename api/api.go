@@ -1,20 +1,56 @@
 package api
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"sync/atomic"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/joac1144/bootdev-chirpy/internal/auth"
 	"github.com/joac1144/bootdev-chirpy/internal/database"
+	"github.com/joac1144/bootdev-chirpy/internal/moderation"
+	"github.com/joac1144/bootdev-chirpy/internal/ratelimit"
+	"github.com/lib/pq"
 )
 
+// accessTokenAudience is the "aud" claim of the access tokens the API issues
+// and accepts.
+const accessTokenAudience = "chirpy-api"
+
 type ApiConfig struct {
+	DbConn         *sql.DB
 	Db             *database.Queries
 	FileserverHits atomic.Int32
 	Platform       string
-	Secret         string
-	PolkaApiKey    string
+	// Keys signs access tokens and verifies them.
+	Keys *auth.Keyring
+	// TokenAlgorithms are the algorithms access tokens may be signed with;
+	// any algorithm of Keys is accepted if it is empty.
+	TokenAlgorithms []string
+	// ClockSkew is how far the clocks of token issuers may be off.
+	ClockSkew           time.Duration
+	PolkaApiKey         string
+	Moderation          moderation.Filter
+	ModerationRulesFile string
+	// AdminEmail is made an admin when it signs up and there is no admin
+	// yet, so one can be had again after a reset.
+	AdminEmail string
+	// RateLimitStore keeps the buckets of rate limited routes. Requests are
+	// not limited if it is nil.
+	RateLimitStore ratelimit.Store
+	// TrustProxy makes the client IP be read from X-Forwarded-For.
+	TrustProxy bool
+	// LoginLockout and LoginIPLockout refuse logins after repeated failures
+	// for an account or from an IP. The zero policy never locks.
+	LoginLockout   auth.LockoutPolicy
+	LoginIPLockout auth.LockoutPolicy
+
+	tokenRevocations tokenRevocations
 }
 
 func (config *ApiConfig) MiddlewareMetricsInc(next http.Handler) http.Handler {
@@ -24,6 +60,92 @@ func (config *ApiConfig) MiddlewareMetricsInc(next http.Handler) http.Handler {
 	})
 }
 
+// authenticate returns the ID of the user the request's bearer token belongs to.
+func (config *ApiConfig) authenticate(req *http.Request) (uuid.UUID, error) {
+	claims, err := config.claims(req)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return claims.UserID()
+}
+
+// claims validates the request's bearer token and returns its claims. Tokens
+// issued before the user's tokens were last revoked are rejected. Personal
+// access tokens are only accepted on routes wrapped in RequireScope, whose
+// claims are passed on in the request context.
+func (config *ApiConfig) claims(req *http.Request) (*auth.Claims, error) {
+	if claims, ok := req.Context().Value(claimsKey{}).(*auth.Claims); ok {
+		return claims, nil
+	}
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		return nil, err
+	}
+	if auth.IsPersonalAccessToken(token) {
+		return nil, errPersonalAccessTokenNotAllowed
+	}
+	return config.accessTokenClaims(req, token)
+}
+
+func (config *ApiConfig) accessTokenClaims(req *http.Request, token string) (*auth.Claims, error) {
+	claims, err := config.Keys.ParseJWT(token,
+		auth.RequireIssuer(auth.Issuer),
+		auth.RequireAudience(accessTokenAudience),
+		auth.AllowAlgorithms(config.TokenAlgorithms...),
+		auth.AllowClockSkew(config.ClockSkew),
+	)
+	if err != nil {
+		return nil, err
+	}
+	userId, err := claims.UserID()
+	if err != nil {
+		return nil, err
+	}
+	validAfter, err := config.tokenRevocations.validAfter(req.Context(), userId, config.Db.GetTokensValidAfter)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errTokenRevoked
+	}
+	if err != nil {
+		log.Printf("Error checking token revocation: %s", err)
+		return nil, errTokenCheckFailed
+	}
+	if validAfter.Valid && claims.IssuedBefore(validAfter.Time) {
+		return nil, errTokenRevoked
+	}
+	return claims, nil
+}
+
+// viewerId is like authenticate for endpoints that also serve anonymous
+// clients; a missing or invalid token yields a null ID instead of an error.
+func (config *ApiConfig) viewerId(req *http.Request) uuid.NullUUID {
+	userId, err := config.authenticate(req)
+	if err != nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: userId, Valid: true}
+}
+
+// withTx runs fn with queries bound to a single transaction, which is
+// committed if fn succeeds and rolled back otherwise.
+func (config *ApiConfig) withTx(ctx context.Context, fn func(q *database.Queries) error) error {
+	tx, err := config.DbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(config.Db.WithTx(tx))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
 func respond(rw http.ResponseWriter, statusCode int, payload any) {
 	rw.Header().Set("Content-Type", "application/json")
 	data, err := json.Marshal(payload)
@@ -36,6 +158,49 @@ func respond(rw http.ResponseWriter, statusCode int, payload any) {
 	rw.Write(data)
 }
 
+// respondUnauthorized rejects a request whose access token is missing or
+// invalid, with a code telling the client why. A token that could not be
+// checked is a server error instead.
+func respondUnauthorized(rw http.ResponseWriter, err error) {
+	type resError struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}
+
+	if errors.Is(err, errTokenCheckFailed) {
+		respondError(rw, http.StatusInternalServerError, "Failed to check token")
+		return
+	}
+
+	code := "invalid_token"
+	switch {
+	case errors.Is(err, auth.ErrMissingAuthorization):
+		code = "missing_token"
+	case errors.Is(err, auth.ErrTokenExpired):
+		code = "token_expired"
+	case errors.Is(err, auth.ErrTokenNotValidYet):
+		code = "token_not_valid_yet"
+	case errors.Is(err, auth.ErrTokenSignatureInvalid), errors.Is(err, auth.ErrUnknownKey), errors.Is(err, auth.ErrUnexpectedAlgorithm):
+		code = "invalid_signature"
+	case errors.Is(err, auth.ErrTokenWrongIssuer):
+		code = "invalid_issuer"
+	case errors.Is(err, auth.ErrTokenWrongAudience):
+		code = "invalid_audience"
+	case errors.Is(err, errTokenRevoked):
+		code = "token_revoked"
+	case errors.Is(err, errPersonalAccessTokenNotAllowed):
+		code = "personal_access_token_not_allowed"
+	}
+
+	// Clients that sent no token are only told which scheme to use.
+	if code == "missing_token" {
+		rw.Header().Set("WWW-Authenticate", "Bearer")
+	} else {
+		rw.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+	respond(rw, http.StatusUnauthorized, resError{Error: "Unauthorized: " + err.Error(), Code: code})
+}
+
 func respondError(rw http.ResponseWriter, statusCode int, errMsg string) {
 	type resError struct {
 		Error string `json:"error"`
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/joac1144/bootdev-chirpy/internal/database"
+)
+
+const BlockUserPath string = "POST /api/users/{userId}/block"
+const UnblockUserPath string = "DELETE /api/users/{userId}/block"
+const MuteUserPath string = "POST /api/users/{userId}/mute"
+const UnmuteUserPath string = "DELETE /api/users/{userId}/mute"
+
+// BlockUserHandler blocks a user. Blocked users and the blocker can no longer
+// see, follow or reply to each other, and existing follows between them are
+// removed.
+func (config *ApiConfig) BlockUserHandler(rw http.ResponseWriter, req *http.Request) {
+	userId, blockedId, ok := config.userRelationRequest(rw, req, "block")
+	if !ok {
+		return
+	}
+
+	err := config.withTx(req.Context(), func(q *database.Queries) error {
+		err := q.CreateBlock(req.Context(), database.CreateBlockParams{
+			BlockerID: userId,
+			BlockedID: blockedId,
+		})
+		if err != nil {
+			return err
+		}
+		return q.DeleteFollowsBetween(req.Context(), database.DeleteFollowsBetweenParams{
+			UserID:      userId,
+			OtherUserID: blockedId,
+		})
+	})
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusNoContent, nil)
+}
+
+func (config *ApiConfig) UnblockUserHandler(rw http.ResponseWriter, req *http.Request) {
+	userId, blockedId, ok := config.userRelationRequest(rw, req, "unblock")
+	if !ok {
+		return
+	}
+
+	err := config.Db.DeleteBlock(req.Context(), database.DeleteBlockParams{
+		BlockerID: userId,
+		BlockedID: blockedId,
+	})
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusNoContent, nil)
+}
+
+// MuteUserHandler mutes a user, hiding their chirps from the caller's feeds.
+// Unlike blocking, the muted user is not told and can still interact.
+func (config *ApiConfig) MuteUserHandler(rw http.ResponseWriter, req *http.Request) {
+	userId, mutedId, ok := config.userRelationRequest(rw, req, "mute")
+	if !ok {
+		return
+	}
+
+	err := config.Db.CreateMute(req.Context(), database.CreateMuteParams{
+		MuterID: userId,
+		MutedID: mutedId,
+	})
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusNoContent, nil)
+}
+
+func (config *ApiConfig) UnmuteUserHandler(rw http.ResponseWriter, req *http.Request) {
+	userId, mutedId, ok := config.userRelationRequest(rw, req, "unmute")
+	if !ok {
+		return
+	}
+
+	err := config.Db.DeleteMute(req.Context(), database.DeleteMuteParams{
+		MuterID: userId,
+		MutedID: mutedId,
+	})
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusNoContent, nil)
+}
+
+// userRelationRequest authenticates the caller of a block or mute endpoint
+// and checks the user in the path, returning both IDs.
+func (config *ApiConfig) userRelationRequest(rw http.ResponseWriter, req *http.Request, verb string) (uuid.UUID, uuid.UUID, bool) {
+	otherUserId, err := uuid.Parse(req.PathValue("userId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid user ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	if otherUserId == userId {
+		respondError(rw, http.StatusBadRequest, "You cannot "+verb+" yourself")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	_, err = config.Db.GetUserById(req.Context(), otherUserId)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(rw, http.StatusNotFound, "User not found")
+		return uuid.Nil, uuid.Nil, false
+	}
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userId, otherUserId, true
+}
+
+// isBlocked reports whether either user has blocked the other.
+func (config *ApiConfig) isBlocked(ctx context.Context, userId, otherUserId uuid.UUID) (bool, error) {
+	return config.Db.BlockExists(ctx, database.BlockExistsParams{
+		UserID:      userId,
+		OtherUserID: otherUserId,
+	})
+}
+
+// blockedUserIds returns the users the viewer has blocked or been blocked by.
+func (config *ApiConfig) blockedUserIds(ctx context.Context, viewerId uuid.NullUUID) (map[uuid.UUID]bool, error) {
+	blocked := map[uuid.UUID]bool{}
+	if !viewerId.Valid {
+		return blocked, nil
+	}
+	userIds, err := config.Db.ListBlockedUserIds(ctx, viewerId.UUID)
+	if err != nil {
+		return nil, err
+	}
+	for _, userId := range userIds {
+		blocked[userId] = true
+	}
+	return blocked, nil
+}
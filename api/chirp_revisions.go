@@ -0,0 +1,66 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/joac1144/bootdev-chirpy/models"
+)
+
+const GetChirpRevisionsPath string = "GET /api/chirps/{chirpId}/revisions"
+
+func (config *ApiConfig) GetChirpRevisionsHandler(rw http.ResponseWriter, req *http.Request) {
+	chirpId, err := uuid.Parse(req.PathValue("chirpId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid chirp ID")
+		return
+	}
+
+	chirp, err := config.Db.GetChirpById(req.Context(), chirpId)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(rw, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	view := config.chirpViewFor(req)
+	if !view.canSee(chirp) {
+		respondError(rw, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	if view.viewerId.Valid {
+		blocked, err := config.isBlocked(req.Context(), view.viewerId.UUID, chirp.UserID)
+		if err != nil {
+			respondError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if blocked {
+			respondError(rw, http.StatusNotFound, "Chirp not found")
+			return
+		}
+	}
+
+	revisions, err := config.Db.ListChirpRevisions(req.Context(), chirpId)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	mappedRevisions := make([]models.ChirpRevision, len(revisions))
+	for i, revision := range revisions {
+		mappedRevisions[i] = models.ChirpRevision{
+			ID:         revision.ID,
+			ChirpID:    revision.ChirpID,
+			Body:       revision.Body,
+			CreatedAt:  revision.CreatedAt,
+			ReplacedAt: revision.ReplacedAt,
+		}
+	}
+
+	respond(rw, http.StatusOK, mappedRevisions)
+}
@@ -1,10 +1,15 @@
 package api
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/joac1144/bootdev-chirpy/internal/auth"
@@ -15,8 +20,11 @@ import (
 const GetChirpPath string = "GET /api/chirps/{chirpId}"
 const GetChirpsPath string = "GET /api/chirps"
 const PostChirpsPath string = "POST /api/chirps"
+const UpdateChirpPath string = "PUT /api/chirps/{chirpId}"
 const DeleteChirpPath string = "DELETE /api/chirps/{chirpId}"
 
+const maxChirpLength = 140
+
 func (config *ApiConfig) GetChirpHandler(rw http.ResponseWriter, req *http.Request) {
 	chirpId, err := uuid.Parse(req.PathValue("chirpId"))
 	if err != nil {
@@ -29,117 +37,454 @@ func (config *ApiConfig) GetChirpHandler(rw http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	mappedChirp := models.Chirp{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserID,
+	view := config.chirpViewFor(req)
+	if !view.canSee(chirp) {
+		respondError(rw, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	if view.viewerId.Valid {
+		blocked, err := config.isBlocked(req.Context(), view.viewerId.UUID, chirp.UserID)
+		if err != nil {
+			respondError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if blocked {
+			respondError(rw, http.StatusNotFound, "Chirp not found")
+			return
+		}
+	}
+
+	mappedChirps, err := config.mapChirps(req.Context(), []database.Chirp{chirp}, view)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
 	}
-	respond(rw, http.StatusOK, mappedChirp)
+	respond(rw, http.StatusOK, mappedChirps[0])
 }
 
 func (config *ApiConfig) GetChirpsHandler(rw http.ResponseWriter, req *http.Request) {
+	params, limit, err := parseListChirpsParams(req.URL.Query())
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	authorId := req.URL.Query().Get("author_id")
-	sortBy := req.URL.Query().Get("sort")
+	view := config.chirpViewFor(req)
+	params.filter.ViewerID = view.viewerId
+	params.filter.IncludeHidden = view.canSeeHidden()
+
+	chirps, err := config.listChirps(req.Context(), params)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
 
-	var chirps []database.Chirp
-	var err error
+	page := models.ChirpPage{}
+	if len(chirps) > limit {
+		chirps = chirps[:limit]
+		last := chirps[limit-1]
+		sortValue := last.CreatedAt
+		if params.sortBy == "updated_at" {
+			sortValue = last.UpdatedAt
+		}
+		page.NextCursor = pageCursor{Time: sortValue, ID: last.ID, Sort: params.sort()}.encode()
+	}
 
-	if authorId != "" {
-		authorUUID, err := uuid.Parse(authorId)
-		if err != nil {
-			respondError(rw, http.StatusBadRequest, "Invalid author ID")
+	page.Chirps, err = config.mapChirps(req.Context(), chirps, view)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusOK, page)
+}
+
+// listChirpsParams are the parameters of GET /api/chirps. Each sort order has
+// its own query so Postgres can walk the matching index; they all share the
+// same filters.
+type listChirpsParams struct {
+	filter   database.ListChirpsByCreatedAtParams
+	sortBy   string
+	sortDesc bool
+}
+
+// sort identifies the sort order in page cursors, so a cursor can't be used
+// to continue a listing in a different order.
+func (params listChirpsParams) sort() string {
+	if params.sortDesc {
+		return params.sortBy + ":desc"
+	}
+	return params.sortBy + ":asc"
+}
+
+func (config *ApiConfig) listChirps(ctx context.Context, params listChirpsParams) ([]database.Chirp, error) {
+	switch {
+	case params.sortBy == "updated_at" && params.sortDesc:
+		return config.Db.ListChirpsByUpdatedAtDesc(ctx, database.ListChirpsByUpdatedAtDescParams(params.filter))
+	case params.sortBy == "updated_at":
+		return config.Db.ListChirpsByUpdatedAt(ctx, database.ListChirpsByUpdatedAtParams(params.filter))
+	case params.sortDesc:
+		return config.Db.ListChirpsByCreatedAtDesc(ctx, database.ListChirpsByCreatedAtDescParams(params.filter))
+	default:
+		return config.Db.ListChirpsByCreatedAt(ctx, params.filter)
+	}
+}
+
+// parseListChirpsParams turns the query string of GET /api/chirps into
+// listChirpsParams. The returned page size is one more than the limit so the
+// handler can tell whether there is a next page.
+func parseListChirpsParams(query url.Values) (listChirpsParams, int, error) {
+	params := listChirpsParams{filter: database.ListChirpsByCreatedAtParams{AuthorIds: []uuid.UUID{}}}
+
+	limit, cursor, err := parsePageParams(query)
+	if err != nil {
+		return params, 0, err
+	}
+	params.filter.PageSize = int32(limit + 1)
+
+	switch query.Get("sort") {
+	case "", "asc":
+	case "desc":
+		params.sortDesc = true
+	default:
+		return params, 0, errors.New("invalid parameter 'sort': must be 'asc' or 'desc'")
+	}
+
+	switch sortBy := query.Get("sort_by"); sortBy {
+	case "":
+		params.sortBy = "created_at"
+	case "created_at", "updated_at":
+		params.sortBy = sortBy
+	default:
+		return params, 0, errors.New("invalid parameter 'sort_by': must be 'created_at' or 'updated_at'")
+	}
+
+	if cursor != nil {
+		if cursor.Sort != params.sort() {
+			return params, 0, errors.New("invalid parameter 'cursor': does not match 'sort' and 'sort_by'")
+		}
+		params.filter.CursorTime = sql.NullTime{Time: cursor.Time, Valid: true}
+		params.filter.CursorID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+	}
+
+	for _, value := range query["author_id"] {
+		for _, authorId := range strings.Split(value, ",") {
+			authorUUID, err := uuid.Parse(strings.TrimSpace(authorId))
+			if err != nil {
+				return params, 0, errors.New("invalid parameter 'author_id': '" + authorId + "' is not a valid ID")
+			}
+			params.filter.AuthorIds = append(params.filter.AuthorIds, authorUUID)
+		}
+	}
+
+	params.filter.Since, err = parseTimeParam(query, "since")
+	if err != nil {
+		return params, 0, err
+	}
+	params.filter.Until, err = parseTimeParam(query, "until")
+	if err != nil {
+		return params, 0, err
+	}
+	if params.filter.Since.Valid && params.filter.Until.Valid && !params.filter.Since.Time.Before(params.filter.Until.Time) {
+		return params, 0, errors.New("invalid parameters: 'since' must be before 'until'")
+	}
+
+	return params, limit, nil
+}
+
+func parseTimeParam(query url.Values, name string) (sql.NullTime, error) {
+	value := query.Get(name)
+	if value == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return sql.NullTime{}, errors.New("invalid parameter '" + name + "': must be an RFC 3339 timestamp")
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}, nil
+}
+
+// validateChirpBody checks the body of a new or edited chirp of the given
+// kind and returns an error message for an invalid one.
+func validateChirpBody(kind, body string) string {
+	if len(body) > maxChirpLength {
+		return "Chirp is too long"
+	}
+	switch kind {
+	case "rechirp":
+		if body != "" {
+			return "Rechirps cannot have a body"
+		}
+	case "quote":
+		if strings.TrimSpace(body) == "" {
+			return "Quotes must have a body"
+		}
+	default:
+		if strings.TrimSpace(body) == "" {
+			return "Chirp must have a body"
+		}
+	}
+	return ""
+}
+
+func (config *ApiConfig) PostChirpsHandler(rw http.ResponseWriter, req *http.Request) {
+	userId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+	if !config.checkNotSuspended(rw, req, userId) {
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	type reqData struct {
+		Body      string     `json:"body"`
+		InReplyTo *uuid.UUID `json:"in_reply_to"`
+		RechirpOf *uuid.UUID `json:"rechirp_of"`
+		QuoteOf   *uuid.UUID `json:"quote_of"`
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := reqData{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	createParams := database.CreateChirpParams{
+		UserID: userId,
+		Kind:   "chirp",
+	}
+
+	var repostTarget *uuid.UUID
+	switch {
+	case params.RechirpOf != nil && params.QuoteOf != nil:
+		respondError(rw, http.StatusBadRequest, "A chirp cannot be both a rechirp and a quote")
+		return
+	case params.RechirpOf != nil:
+		if params.Body != "" || params.InReplyTo != nil {
+			respondError(rw, http.StatusBadRequest, "Rechirps cannot have a body or be replies")
+			return
+		}
+		createParams.Kind = "rechirp"
+		repostTarget = params.RechirpOf
+	case params.QuoteOf != nil:
+		createParams.Kind = "quote"
+		repostTarget = params.QuoteOf
+	}
+	if errMsg := validateChirpBody(createParams.Kind, params.Body); errMsg != "" {
+		respondError(rw, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	if repostTarget != nil {
+		original, err := config.getOriginalChirp(req.Context(), *repostTarget, userId)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondError(rw, http.StatusBadRequest, "The chirp you are reposting does not exist")
 			return
 		}
-		chirps, err = config.Db.GetChirpsByAuthorId(req.Context(), authorUUID)
 		if err != nil {
 			respondError(rw, http.StatusInternalServerError, err.Error())
 			return
 		}
-	} else {
-		chirps, err = config.Db.GetChirps(req.Context())
+		if createParams.Kind == "rechirp" {
+			createParams.RechirpOf = uuid.NullUUID{UUID: original.ID, Valid: true}
+		} else {
+			createParams.RepostOf = uuid.NullUUID{UUID: original.ID, Valid: true}
+		}
+	}
+
+	if params.InReplyTo != nil {
+		parent, err := config.getOriginalChirp(req.Context(), *params.InReplyTo, userId)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondError(rw, http.StatusBadRequest, "The chirp you are replying to does not exist")
+			return
+		}
 		if err != nil {
 			respondError(rw, http.StatusInternalServerError, err.Error())
 			return
 		}
+		createParams.InReplyTo = uuid.NullUUID{UUID: parent.ID, Valid: true}
 	}
 
-	if sortBy == "desc" {
-		slices.SortFunc(chirps, func(a, b database.Chirp) int {
-			if a.CreatedAt.Before(b.CreatedAt) {
-				return 1
-			} else if a.CreatedAt.After(b.CreatedAt) {
-				return -1
-			}
-			return 0
-		})
+	moderated := config.Moderation.Apply(params.Body)
+	if moderated.Rejected {
+		respondError(rw, http.StatusBadRequest, "Chirp contains prohibited language")
+		return
 	}
+	createParams.Body = moderated.Body
 
-	mappedChirps := make([]models.Chirp, len(chirps))
-	for i, chirp := range chirps {
-		mappedChirps[i] = models.Chirp{
-			ID:        chirp.ID,
-			CreatedAt: chirp.CreatedAt,
-			UpdatedAt: chirp.UpdatedAt,
-			Body:      chirp.Body,
-			UserID:    chirp.UserID,
+	var chirp database.Chirp
+	err = config.withTx(req.Context(), func(q *database.Queries) error {
+		var err error
+		chirp, err = q.CreateChirp(req.Context(), createParams)
+		if err != nil {
+			return err
+		}
+		err = indexChirpEntities(req.Context(), q, chirp, false)
+		if err != nil {
+			return err
 		}
+		return flagChirp(req.Context(), q, chirp.ID, moderated)
+	})
+	if isUniqueViolation(err) {
+		respondError(rw, http.StatusConflict, "You have already rechirped this chirp")
+		return
+	}
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
 	}
 
-	respond(rw, http.StatusOK, mappedChirps)
+	mappedChirps, err := config.mapChirps(req.Context(), []database.Chirp{chirp}, viewAs(userId))
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respond(rw, http.StatusCreated, mappedChirps[0])
 }
 
-func (config *ApiConfig) PostChirpsHandler(rw http.ResponseWriter, req *http.Request) {
-	token, err := auth.GetBearerToken(req.Header)
+// getOriginalChirp looks up a chirp, following a rechirp to the chirp it
+// repeats so that replies and reposts always point at original content.
+// Chirps the user cannot see, including those of users on the other side of
+// a block, are reported as sql.ErrNoRows.
+func (config *ApiConfig) getOriginalChirp(ctx context.Context, chirpId, userId uuid.UUID) (database.Chirp, error) {
+	chirp, err := config.Db.GetChirpById(ctx, chirpId)
+	if err == nil && chirp.Kind == "rechirp" {
+		chirp, err = config.Db.GetChirpById(ctx, chirp.RechirpOf.UUID)
+	}
+	if err != nil {
+		return chirp, err
+	}
+	if !viewAs(userId).canSee(chirp) {
+		return database.Chirp{}, sql.ErrNoRows
+	}
+	blocked, err := config.isBlocked(ctx, userId, chirp.UserID)
+	if err != nil {
+		return chirp, err
+	}
+	if blocked {
+		return database.Chirp{}, sql.ErrNoRows
+	}
+	return chirp, nil
+}
+
+// checkNotSuspended writes an error response if the user has been suspended
+// by a moderator.
+func (config *ApiConfig) checkNotSuspended(rw http.ResponseWriter, req *http.Request, userId uuid.UUID) bool {
+	user, err := config.Db.GetUserById(req.Context(), userId)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return false
+	}
+	if user.SuspendedAt.Valid {
+		respondError(rw, http.StatusForbidden, "Your account is suspended")
+		return false
+	}
+	return true
+}
+
+func (config *ApiConfig) UpdateChirpHandler(rw http.ResponseWriter, req *http.Request) {
+	chirpId, err := uuid.Parse(req.PathValue("chirpId"))
 	if err != nil {
-		respondError(rw, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		respondError(rw, http.StatusBadRequest, "Invalid chirp ID")
 		return
 	}
-	userId, err := auth.ValidateJWT(token, config.Secret)
+
+	accessTokenUserId, err := config.authenticate(req)
 	if err != nil {
-		respondError(rw, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		respondUnauthorized(rw, err)
+		return
+	}
+	if !config.checkNotSuspended(rw, req, accessTokenUserId) {
 		return
 	}
 
-	rw.Header().Set("Content-Type", "application/json")
 	type reqData struct {
 		Body string `json:"body"`
 	}
-
 	decoder := json.NewDecoder(req.Body)
 	params := reqData{}
 	err = decoder.Decode(&params)
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	chirp, err := config.Db.GetChirpById(req.Context(), chirpId)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(rw, http.StatusNotFound, "Chirp not found")
+		return
+	}
 	if err != nil {
 		respondError(rw, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	if len(params.Body) > 140 {
-		respondError(rw, http.StatusBadRequest, "Chirp is too long")
+	if chirp.UserID != accessTokenUserId {
+		respondError(rw, http.StatusForbidden, "You are not allowed to edit this chirp")
 		return
 	}
 
-	cleanedBody := cleanBody(params.Body)
+	if chirp.Kind == "rechirp" {
+		respondError(rw, http.StatusBadRequest, "Rechirps cannot be edited")
+		return
+	}
+	if errMsg := validateChirpBody(chirp.Kind, params.Body); errMsg != "" {
+		respondError(rw, http.StatusBadRequest, errMsg)
+		return
+	}
 
-	chirp, err := config.Db.CreateChirp(req.Context(), database.CreateChirpParams{
-		Body:   cleanedBody,
-		UserID: userId,
-	})
-	if err != nil {
-		respondError(rw, http.StatusInternalServerError, err.Error())
+	moderated := config.Moderation.Apply(params.Body)
+	if moderated.Rejected {
+		respondError(rw, http.StatusBadRequest, "Chirp contains prohibited language")
 		return
 	}
 
-	mappedChirp := models.Chirp{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserID,
+	updatedChirp := chirp
+	if moderated.Body != chirp.Body {
+		err = config.withTx(req.Context(), func(q *database.Queries) error {
+			// Lock the chirp so concurrent edits each record the body they
+			// replaced.
+			current, err := q.GetChirpForUpdate(req.Context(), chirpId)
+			if err != nil {
+				return err
+			}
+			err = q.CreateChirpRevision(req.Context(), database.CreateChirpRevisionParams{
+				ChirpID:   chirpId,
+				Body:      current.Body,
+				CreatedAt: current.UpdatedAt,
+			})
+			if err != nil {
+				return err
+			}
+			updatedChirp, err = q.UpdateChirpBody(req.Context(), database.UpdateChirpBodyParams{
+				Body: moderated.Body,
+				ID:   chirpId,
+			})
+			if err != nil {
+				return err
+			}
+			err = indexChirpEntities(req.Context(), q, updatedChirp, true)
+			if err != nil {
+				return err
+			}
+			return flagChirp(req.Context(), q, chirpId, moderated)
+		})
+		if err != nil {
+			respondError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
 	}
-	respond(rw, http.StatusCreated, mappedChirp)
+
+	mappedChirps, err := config.mapChirps(req.Context(), []database.Chirp{updatedChirp}, viewAs(accessTokenUserId))
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respond(rw, http.StatusOK, mappedChirps[0])
 }
 
 func (config *ApiConfig) DeleteChirpHandler(rw http.ResponseWriter, req *http.Request) {
@@ -149,14 +494,9 @@ func (config *ApiConfig) DeleteChirpHandler(rw http.ResponseWriter, req *http.Re
 		return
 	}
 
-	accessToken, err := auth.GetBearerToken(req.Header)
-	if err != nil {
-		respondError(rw, http.StatusUnauthorized, "Unauthorized: "+err.Error())
-		return
-	}
-	accessTokenUserId, err := auth.ValidateJWT(accessToken, config.Secret)
+	accessTokenUserId, err := config.authenticate(req)
 	if err != nil {
-		respondError(rw, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		respondUnauthorized(rw, err)
 		return
 	}
 
@@ -179,16 +519,190 @@ func (config *ApiConfig) DeleteChirpHandler(rw http.ResponseWriter, req *http.Re
 	respond(rw, http.StatusNoContent, nil)
 }
 
-func cleanBody(input string) string {
-	cleanedBody := strings.Split(input, " ")
-	badWords := []string{"kerfuffle", "sharbert", "fornax"}
-	for i := range cleanedBody {
-		lowercase := strings.ToLower(cleanedBody[i])
-		for _, badWord := range badWords {
-			if lowercase == badWord {
-				cleanedBody[i] = "****"
+// chirpView describes who is looking at a batch of chirps and what should be
+// embedded in them.
+type chirpView struct {
+	viewerId       uuid.NullUUID
+	viewerRole     string
+	includeAuthors bool
+}
+
+// chirpViewFor builds the chirp view of a request: the caller, if a valid
+// bearer token is present, and the embeds asked for with "include=author".
+func (config *ApiConfig) chirpViewFor(req *http.Request) chirpView {
+	view := chirpView{
+		includeAuthors: slices.Contains(strings.Split(req.URL.Query().Get("include"), ","), "author"),
+	}
+	claims, err := config.claims(req)
+	if err != nil {
+		return view
+	}
+	userId, err := claims.UserID()
+	if err != nil {
+		return view
+	}
+	view.viewerId = uuid.NullUUID{UUID: userId, Valid: true}
+	view.viewerRole = claims.Role
+	return view
+}
+
+// viewAs is the view of a user acting on chirps, without moderator rights.
+func viewAs(userId uuid.UUID) chirpView {
+	return chirpView{viewerId: uuid.NullUUID{UUID: userId, Valid: true}}
+}
+
+// canSeeHidden reports whether the viewer may see every chirp hidden by
+// moderators.
+func (view chirpView) canSeeHidden() bool {
+	return view.viewerId.Valid && auth.HasRole(view.viewerRole, auth.RoleModerator)
+}
+
+// canSee reports whether a chirp is visible to the viewer. Hidden chirps are
+// only visible to their author and moderators.
+func (view chirpView) canSee(chirp database.Chirp) bool {
+	if !chirp.HiddenAt.Valid || view.canSeeHidden() {
+		return true
+	}
+	return view.viewerId.Valid && view.viewerId.UUID == chirp.UserID
+}
+
+// mapChirps maps chirps to their API representation. The originals of
+// rechirps and quotes are embedded unless the viewer cannot see them, and the
+// like counts, mentions and author profiles of the whole batch are each filled
+// in with a single query.
+func (config *ApiConfig) mapChirps(ctx context.Context, chirps []database.Chirp, view chirpView) ([]models.Chirp, error) {
+	if len(chirps) == 0 {
+		return []models.Chirp{}, nil
+	}
+
+	originalIds := []uuid.UUID{}
+	for _, chirp := range chirps {
+		if originalId := originalOf(chirp); originalId.Valid {
+			originalIds = append(originalIds, originalId.UUID)
+		}
+	}
+	originals := []database.Chirp{}
+	if len(originalIds) > 0 {
+		var err error
+		originals, err = config.Db.ListChirpsByIds(ctx, originalIds)
+		if err != nil {
+			return nil, err
+		}
+		blocked, err := config.blockedUserIds(ctx, view.viewerId)
+		if err != nil {
+			return nil, err
+		}
+		originals = slices.DeleteFunc(originals, func(original database.Chirp) bool {
+			return !view.canSee(original) || blocked[original.UserID]
+		})
+	}
+
+	all := append(slices.Clone(chirps), originals...)
+	mapped := make([]models.Chirp, len(all))
+	indexes := make(map[uuid.UUID]int, len(all))
+	chirpIds := make([]uuid.UUID, len(all))
+	for i, chirp := range all {
+		mapped[i] = mapChirp(chirp)
+		indexes[chirp.ID] = i
+		chirpIds[i] = chirp.ID
+	}
+
+	likeStats, err := config.Db.ListChirpLikeStats(ctx, database.ListChirpLikeStatsParams{
+		ViewerID: view.viewerId,
+		ChirpIds: chirpIds,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, stats := range likeStats {
+		mapped[indexes[stats.ChirpID]].LikeCount = stats.LikeCount
+		mapped[indexes[stats.ChirpID]].LikedByMe = stats.LikedByMe
+	}
+
+	mentions, err := config.Db.ListChirpMentions(ctx, chirpIds)
+	if err != nil {
+		return nil, err
+	}
+	for _, mention := range mentions {
+		i := indexes[mention.ChirpID]
+		mapped[i].Mentions = append(mapped[i].Mentions, models.Mention{
+			UserID:   mention.UserID,
+			Username: mention.Username.String,
+			Start:    mention.StartOffset,
+			End:      mention.EndOffset,
+		})
+	}
+
+	if view.includeAuthors {
+		authorIds := make([]uuid.UUID, len(all))
+		for i, chirp := range all {
+			authorIds[i] = chirp.UserID
+		}
+		authors, err := config.Db.ListUsersByIds(ctx, authorIds)
+		if err != nil {
+			return nil, err
+		}
+		profiles := make(map[uuid.UUID]models.Profile, len(authors))
+		for _, author := range authors {
+			profiles[author.ID] = mapProfile(author)
+		}
+		for i := range mapped {
+			if profile, ok := profiles[mapped[i].UserID]; ok {
+				mapped[i].Author = &profile
 			}
 		}
 	}
-	return strings.Join(cleanedBody, " ")
+
+	mappedChirps := mapped[:len(chirps)]
+	for i := range mappedChirps {
+		if mappedChirps[i].Kind == "chirp" {
+			continue
+		}
+		if mappedChirps[i].RepostOf == nil {
+			mappedChirps[i].OriginalUnavailable = true
+			continue
+		}
+		index, ok := indexes[*mappedChirps[i].RepostOf]
+		if !ok {
+			mappedChirps[i].OriginalUnavailable = true
+			continue
+		}
+		original := mapped[index]
+		mappedChirps[i].Original = &original
+	}
+
+	return mappedChirps, nil
+}
+
+func mapChirp(chirp database.Chirp) models.Chirp {
+	mapped := models.Chirp{
+		ID:        chirp.ID,
+		CreatedAt: chirp.CreatedAt,
+		UpdatedAt: chirp.UpdatedAt,
+		Body:      chirp.Body,
+		UserID:    chirp.UserID,
+		Kind:      chirp.Kind,
+		Mentions:  []models.Mention{},
+		Hidden:    chirp.HiddenAt.Valid,
+	}
+	if chirp.EditedAt.Valid {
+		mapped.EditedAt = &chirp.EditedAt.Time
+	}
+	if chirp.InReplyTo.Valid {
+		mapped.InReplyTo = &chirp.InReplyTo.UUID
+	}
+	if originalId := originalOf(chirp); originalId.Valid {
+		mapped.RepostOf = &originalId.UUID
+	}
+	return mapped
+}
+
+// originalOf returns the chirp a rechirp or quote reposts. Rechirps and
+// quotes keep it in different columns, since only rechirps are deleted with
+// their original.
+func originalOf(chirp database.Chirp) uuid.NullUUID {
+	if chirp.Kind == "rechirp" {
+		return chirp.RechirpOf
+	}
+	return chirp.RepostOf
 }
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/joac1144/bootdev-chirpy/internal/database"
+	"github.com/joac1144/bootdev-chirpy/internal/entities"
+)
+
+// indexChirpEntities stores the hashtags and resolved @mentions found in a
+// chirp's body. Edited chirps are re-indexed from scratch, so replace drops the
+// old entities first. Hashtags are dated by the chirp, not the edit, so editing
+// an old chirp doesn't bring its hashtags back into trending.
+func indexChirpEntities(ctx context.Context, q *database.Queries, chirp database.Chirp, replace bool) error {
+	if replace {
+		err := q.DeleteChirpHashtags(ctx, chirp.ID)
+		if err != nil {
+			return err
+		}
+		err = q.DeleteChirpMentions(ctx, chirp.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	tags := entities.Hashtags(chirp.Body)
+	if len(tags) > 0 {
+		err := q.CreateChirpHashtags(ctx, database.CreateChirpHashtagsParams{
+			ChirpID:   chirp.ID,
+			Tags:      tags,
+			CreatedAt: chirp.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return indexChirpMentions(ctx, q, chirp)
+}
+
+// indexChirpMentions resolves the @username tokens of a chirp against users
+// and stores the ones that belong to an existing user.
+func indexChirpMentions(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	mentions := entities.Mentions(chirp.Body)
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	usernames := make([]string, len(mentions))
+	for i, mention := range mentions {
+		usernames[i] = strings.ToLower(mention.Username)
+	}
+	users, err := q.ListUsersByUsernames(ctx, usernames)
+	if err != nil {
+		return err
+	}
+	userIds := make(map[string]uuid.UUID, len(users))
+	for _, user := range users {
+		userIds[strings.ToLower(user.Username.String)] = user.ID
+	}
+
+	params := database.CreateChirpMentionsParams{ChirpID: chirp.ID}
+	for _, mention := range mentions {
+		userId, ok := userIds[strings.ToLower(mention.Username)]
+		if !ok {
+			continue
+		}
+		params.UserIds = append(params.UserIds, userId)
+		params.StartOffsets = append(params.StartOffsets, int32(mention.Start))
+		params.EndOffsets = append(params.EndOffsets, int32(mention.End))
+	}
+	if len(params.UserIds) == 0 {
+		return nil
+	}
+	return q.CreateChirpMentions(ctx, params)
+}
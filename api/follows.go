@@ -0,0 +1,204 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/joac1144/bootdev-chirpy/internal/database"
+	"github.com/joac1144/bootdev-chirpy/models"
+)
+
+const FollowUserPath string = "POST /api/users/{userId}/follow"
+const UnfollowUserPath string = "DELETE /api/users/{userId}/follow"
+const GetFollowersPath string = "GET /api/users/{userId}/followers"
+const GetFollowingPath string = "GET /api/users/{userId}/following"
+
+func (config *ApiConfig) FollowUserHandler(rw http.ResponseWriter, req *http.Request) {
+	followeeId, err := uuid.Parse(req.PathValue("userId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	userId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+
+	if followeeId == userId {
+		respondError(rw, http.StatusBadRequest, "You cannot follow yourself")
+		return
+	}
+
+	_, err = config.Db.GetUserById(req.Context(), followeeId)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(rw, http.StatusNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	blocked, err := config.isBlocked(req.Context(), userId, followeeId)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if blocked {
+		respondError(rw, http.StatusForbidden, "You cannot follow this user")
+		return
+	}
+
+	err = config.Db.CreateFollow(req.Context(), database.CreateFollowParams{
+		FollowerID: userId,
+		FolloweeID: followeeId,
+	})
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusNoContent, nil)
+}
+
+func (config *ApiConfig) UnfollowUserHandler(rw http.ResponseWriter, req *http.Request) {
+	followeeId, err := uuid.Parse(req.PathValue("userId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	userId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+
+	err = config.Db.DeleteFollow(req.Context(), database.DeleteFollowParams{
+		FollowerID: userId,
+		FolloweeID: followeeId,
+	})
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusNoContent, nil)
+}
+
+func (config *ApiConfig) GetFollowersHandler(rw http.ResponseWriter, req *http.Request) {
+	userId, err := uuid.Parse(req.PathValue("userId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	limit, cursor, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	blocked, ok := config.followListBlocks(rw, req, userId)
+	if !ok {
+		return
+	}
+
+	params := database.ListFollowersParams{
+		UserID:   userId,
+		PageSize: int32(limit + 1),
+	}
+	if cursor != nil {
+		params.CursorTime = sql.NullTime{Time: cursor.Time, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+	}
+
+	followers, err := config.Db.ListFollowers(req.Context(), params)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	follows := make([]models.Follow, len(followers))
+	for i, follower := range followers {
+		follows[i] = models.Follow{UserID: follower.UserID, FollowedAt: follower.CreatedAt}
+	}
+	respond(rw, http.StatusOK, followPage(follows, limit, blocked))
+}
+
+func (config *ApiConfig) GetFollowingHandler(rw http.ResponseWriter, req *http.Request) {
+	userId, err := uuid.Parse(req.PathValue("userId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	limit, cursor, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	blocked, ok := config.followListBlocks(rw, req, userId)
+	if !ok {
+		return
+	}
+
+	params := database.ListFollowingParams{
+		UserID:   userId,
+		PageSize: int32(limit + 1),
+	}
+	if cursor != nil {
+		params.CursorTime = sql.NullTime{Time: cursor.Time, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+	}
+
+	following, err := config.Db.ListFollowing(req.Context(), params)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	follows := make([]models.Follow, len(following))
+	for i, followee := range following {
+		follows[i] = models.Follow{UserID: followee.UserID, FollowedAt: followee.CreatedAt}
+	}
+	respond(rw, http.StatusOK, followPage(follows, limit, blocked))
+}
+
+// followListBlocks returns the users the viewer of a follower or following
+// list has blocked or been blocked by. When that includes the list's owner it
+// responds with 404 and returns false.
+func (config *ApiConfig) followListBlocks(rw http.ResponseWriter, req *http.Request, userId uuid.UUID) (map[uuid.UUID]bool, bool) {
+	blocked, err := config.blockedUserIds(req.Context(), config.viewerId(req))
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if blocked[userId] {
+		respondError(rw, http.StatusNotFound, "User not found")
+		return nil, false
+	}
+	return blocked, true
+}
+
+// followPage trims a list fetched with one extra row down to the limit and
+// sets the next cursor when that extra row was there. Blocked users are left
+// out afterwards, so a page can have fewer users than the limit.
+func followPage(follows []models.Follow, limit int, blocked map[uuid.UUID]bool) models.FollowPage {
+	page := models.FollowPage{Users: follows}
+	if len(follows) > limit {
+		page.Users = follows[:limit]
+		last := page.Users[limit-1]
+		page.NextCursor = pageCursor{Time: last.FollowedAt, ID: last.UserID}.encode()
+	}
+	page.Users = slices.DeleteFunc(page.Users, func(follow models.Follow) bool {
+		return blocked[follow.UserID]
+	})
+	return page
+}
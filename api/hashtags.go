@@ -0,0 +1,110 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joac1144/bootdev-chirpy/internal/database"
+	"github.com/joac1144/bootdev-chirpy/internal/entities"
+	"github.com/joac1144/bootdev-chirpy/models"
+)
+
+const GetHashtagChirpsPath string = "GET /api/hashtags/{tag}/chirps"
+const GetTrendingPath string = "GET /api/trending"
+
+const defaultTrendingWindow = 24 * time.Hour
+const maxTrendingWindow = 7 * 24 * time.Hour
+const defaultTrendingLimit = 10
+const maxTrendingLimit = 50
+
+func (config *ApiConfig) GetHashtagChirpsHandler(rw http.ResponseWriter, req *http.Request) {
+	tag := entities.NormalizeHashtag(req.PathValue("tag"))
+	if tag == "" {
+		respondError(rw, http.StatusBadRequest, "Invalid hashtag")
+		return
+	}
+
+	limit, cursor, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	view := config.chirpViewFor(req)
+	params := database.ListHashtagChirpsParams{
+		Tag:      tag,
+		ViewerID: view.viewerId,
+		PageSize: int32(limit + 1),
+	}
+	if cursor != nil {
+		params.CursorTime = sql.NullTime{Time: cursor.Time, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+	}
+
+	chirps, err := config.Db.ListHashtagChirps(req.Context(), params)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	page := models.ChirpPage{}
+	if len(chirps) > limit {
+		chirps = chirps[:limit]
+		last := chirps[limit-1]
+		page.NextCursor = pageCursor{Time: last.CreatedAt, ID: last.ID}.encode()
+	}
+
+	page.Chirps, err = config.mapChirps(req.Context(), chirps, view)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusOK, page)
+}
+
+func (config *ApiConfig) GetTrendingHandler(rw http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	window := defaultTrendingWindow
+	if s := query.Get("window"); s != "" {
+		w, err := time.ParseDuration(s)
+		if err != nil || w < time.Minute || w > maxTrendingWindow {
+			respondError(rw, http.StatusBadRequest, "invalid parameter 'window': must be a duration between 1m and "+maxTrendingWindow.String())
+			return
+		}
+		window = w
+	}
+
+	limit := defaultTrendingLimit
+	if s := query.Get("limit"); s != "" {
+		l, err := strconv.Atoi(s)
+		if err != nil || l < 1 || l > maxTrendingLimit {
+			respondError(rw, http.StatusBadRequest, "invalid parameter 'limit': must be a number between 1 and "+strconv.Itoa(maxTrendingLimit))
+			return
+		}
+		limit = l
+	}
+
+	trending, err := config.Db.ListTrendingHashtags(req.Context(), database.ListTrendingHashtagsParams{
+		WindowSeconds: int32(window.Seconds()),
+		MaxTags:       int32(limit),
+	})
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	mappedTrending := make([]models.TrendingHashtag, len(trending))
+	for i, hashtag := range trending {
+		mappedTrending[i] = models.TrendingHashtag{
+			Tag:        hashtag.Tag,
+			ChirpCount: hashtag.ChirpCount,
+		}
+	}
+
+	respond(rw, http.StatusOK, mappedTrending)
+}
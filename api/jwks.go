@@ -0,0 +1,12 @@
+package api
+
+import "net/http"
+
+const JWKSPath string = "GET /.well-known/jwks.json"
+
+// JWKSHandler publishes the public keys access tokens are signed with, so
+// other services can verify tokens without sharing a secret.
+func (config *ApiConfig) JWKSHandler(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Cache-Control", "public, max-age=300")
+	respond(rw, http.StatusOK, config.Keys.JWKS())
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/joac1144/bootdev-chirpy/internal/database"
+)
+
+const LikeChirpPath string = "POST /api/chirps/{chirpId}/likes"
+const UnlikeChirpPath string = "DELETE /api/chirps/{chirpId}/likes"
+
+func (config *ApiConfig) LikeChirpHandler(rw http.ResponseWriter, req *http.Request) {
+	chirpId, err := uuid.Parse(req.PathValue("chirpId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid chirp ID")
+		return
+	}
+
+	userId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+
+	chirp, err := config.Db.GetChirpById(req.Context(), chirpId)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(rw, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !config.chirpViewFor(req).canSee(chirp) {
+		respondError(rw, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	blocked, err := config.isBlocked(req.Context(), userId, chirp.UserID)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if blocked {
+		respondError(rw, http.StatusNotFound, "Chirp not found")
+		return
+	}
+
+	err = config.Db.CreateChirpLike(req.Context(), database.CreateChirpLikeParams{
+		ChirpID: chirpId,
+		UserID:  userId,
+	})
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusNoContent, nil)
+}
+
+func (config *ApiConfig) UnlikeChirpHandler(rw http.ResponseWriter, req *http.Request) {
+	chirpId, err := uuid.Parse(req.PathValue("chirpId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid chirp ID")
+		return
+	}
+
+	userId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+
+	err = config.Db.DeleteChirpLike(req.Context(), database.DeleteChirpLikeParams{
+		ChirpID: chirpId,
+		UserID:  userId,
+	})
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusNoContent, nil)
+}
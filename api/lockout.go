@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joac1144/bootdev-chirpy/internal/database"
+)
+
+// loginFailureWindow bounds how far back failed logins are counted, so old
+// failures stop counting towards a lockout even without a successful login.
+const loginFailureWindow = 24 * time.Hour
+
+const (
+	errCodeAccountLocked        = "account_locked"
+	errCodeTooManyLoginAttempts = "too_many_login_attempts"
+)
+
+// loginLockout is a refusal to check a password until Until.
+type loginLockout struct {
+	Code       string
+	Until      time.Time
+	RetryAfter time.Duration
+}
+
+// checkLoginLockout returns the lockout that applies to a login for email from
+// ip, or nil if the password may be checked. The account lockout takes
+// precedence over the IP lockout.
+func (config *ApiConfig) checkLoginLockout(ctx context.Context, email, ip string) (*loginLockout, error) {
+	windowSeconds := int32(loginFailureWindow.Seconds())
+
+	account, err := config.Db.GetAccountLoginFailures(ctx, database.GetAccountLoginFailuresParams{
+		Email:         email,
+		WindowSeconds: windowSeconds,
+	})
+	if err != nil {
+		return nil, err
+	}
+	delay := config.LoginLockout.Delay(int(account.Failures))
+	if until := account.LastFailure.Add(delay); delay > 0 && until.After(account.Now) {
+		return &loginLockout{Code: errCodeAccountLocked, Until: until, RetryAfter: until.Sub(account.Now)}, nil
+	}
+
+	byIP, err := config.Db.GetIPLoginFailures(ctx, database.GetIPLoginFailuresParams{
+		Ip:            ip,
+		WindowSeconds: windowSeconds,
+	})
+	if err != nil {
+		return nil, err
+	}
+	delay = config.LoginIPLockout.Delay(int(byIP.Failures))
+	if until := byIP.LastFailure.Add(delay); delay > 0 && until.After(byIP.Now) {
+		return &loginLockout{Code: errCodeTooManyLoginAttempts, Until: until, RetryAfter: until.Sub(byIP.Now)}, nil
+	}
+	return nil, nil
+}
+
+// recordLoginAttempt stores the outcome of a login. userId is null when the
+// email does not belong to a user.
+func (config *ApiConfig) recordLoginAttempt(ctx context.Context, userId uuid.NullUUID, email, ip string, success bool) error {
+	return config.Db.CreateLoginAttempt(ctx, database.CreateLoginAttemptParams{
+		UserID:  userId,
+		Email:   email,
+		Ip:      ip,
+		Success: success,
+	})
+}
+
+// respondLockout tells the client when it may try to log in again. Locked
+// accounts get 423 so clients can tell them apart from an IP being throttled.
+func respondLockout(rw http.ResponseWriter, lockout *loginLockout) {
+	type response struct {
+		Error       string    `json:"error"`
+		Code        string    `json:"code"`
+		RetryAfter  int       `json:"retry_after"`
+		LockedUntil time.Time `json:"locked_until"`
+	}
+
+	status := http.StatusTooManyRequests
+	msg := "Too many failed login attempts"
+	if lockout.Code == errCodeAccountLocked {
+		status = http.StatusLocked
+		msg = "Account is temporarily locked"
+	}
+
+	seconds := int(math.Ceil(lockout.RetryAfter.Seconds()))
+	rw.Header().Set("Retry-After", strconv.Itoa(seconds))
+	respond(rw, status, response{
+		Error:       msg,
+		Code:        lockout.Code,
+		RetryAfter:  seconds,
+		LockedUntil: lockout.Until.UTC(),
+	})
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -5,8 +5,9 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/joac1144/bootdev-chirpy/internal/auth"
-	"github.com/joac1144/bootdev-chirpy/internal/database"
 	"github.com/joac1144/bootdev-chirpy/models"
 )
 
@@ -25,36 +26,50 @@ func (config *ApiConfig) LoginHandler(rw http.ResponseWriter, req *http.Request)
 		respondError(rw, http.StatusInternalServerError, err.Error())
 		return
 	}
+
+	email := normalizeEmail(params.Email)
+	ip := config.clientIP(req)
+	lockout, err := config.checkLoginLockout(req.Context(), email, ip)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if lockout != nil {
+		respondLockout(rw, lockout)
+		return
+	}
+
 	user, err := config.Db.GetUserByEmail(req.Context(), params.Email)
 	if err != nil {
-		respondError(rw, http.StatusUnauthorized, "Incorrect email or password")
+		config.loginFailed(rw, req, uuid.NullUUID{}, email, ip)
 		return
 	}
 	err = auth.CheckPasswordHash(params.Password, user.HashedPassword)
 	if err != nil {
-		respondError(rw, http.StatusUnauthorized, "Incorrect email or password")
+		config.loginFailed(rw, req, uuid.NullUUID{UUID: user.ID, Valid: true}, email, ip)
 		return
 	}
-
-	token, err := auth.MakeJWT(user.ID, config.Secret, time.Hour)
+	if user.SuspendedAt.Valid {
+		respondError(rw, http.StatusForbidden, "Your account is suspended")
+		return
+	}
+	err = config.recordLoginAttempt(req.Context(), uuid.NullUUID{UUID: user.ID, Valid: true}, email, ip, true)
 	if err != nil {
-		respondError(rw, http.StatusInternalServerError, "Failed to create JWT token")
+		respondError(rw, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	refreshToken, err := auth.MakeRefreshToken()
+	token, err := config.Keys.MakeJWT(user.ID, time.Hour, auth.WithRole(user.Role), auth.ForAudience(accessTokenAudience))
 	if err != nil {
-		respondError(rw, http.StatusInternalServerError, "Failed to create refresh token")
+		respondError(rw, http.StatusInternalServerError, "Failed to create JWT token")
 		return
 	}
 
-	_, err = config.Db.CreateRefreshToken(req.Context(), database.CreateRefreshTokenParams{
-		Token:     refreshToken,
-		UserID:    user.ID,
-		ExpiresAt: time.Now().Add(time.Hour * 24 * 60),
-	})
+	// A login starts a new token family that every rotation of its refresh
+	// token joins.
+	refreshToken, err := config.issueRefreshToken(req, config.Db, user.ID, uuid.New())
 	if err != nil {
-		respondError(rw, http.StatusInternalServerError, "Failed to create refresh token in database")
+		respondError(rw, http.StatusInternalServerError, "Failed to create refresh token: "+err.Error())
 		return
 	}
 
@@ -65,15 +80,20 @@ func (config *ApiConfig) LoginHandler(rw http.ResponseWriter, req *http.Request)
 	}
 
 	resp := response{
-		User: models.User{
-			ID:          user.ID,
-			CreatedAt:   user.CreatedAt,
-			UpdatedAt:   user.UpdatedAt,
-			Email:       user.Email,
-			IsChirpyRed: user.IsChirpyRed,
-		},
+		User:         mapUser(user),
 		AccessToken:  token,
 		RefreshToken: refreshToken,
 	}
 	respond(rw, http.StatusOK, resp)
 }
+
+// loginFailed records a failed login and responds with the same error whether
+// or not the email belongs to a user.
+func (config *ApiConfig) loginFailed(rw http.ResponseWriter, req *http.Request, userId uuid.NullUUID, email, ip string) {
+	err := config.recordLoginAttempt(req.Context(), userId, email, ip, false)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondError(rw, http.StatusUnauthorized, "Incorrect email or password")
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joac1144/bootdev-chirpy/internal/database"
+	"github.com/joac1144/bootdev-chirpy/internal/moderation"
+	"github.com/joac1144/bootdev-chirpy/models"
+)
+
+const GetModerationRulesPath string = "GET /admin/moderation/rules"
+const PutModerationRulePath string = "PUT /admin/moderation/rules/{word}"
+const DeleteModerationRulePath string = "DELETE /admin/moderation/rules/{word}"
+const GetFlaggedChirpsPath string = "GET /admin/moderation/flags"
+const DismissChirpFlagsPath string = "DELETE /admin/moderation/flags/{chirpId}"
+
+// moderationRulesTTL is how long a changed rule can take to reach instances
+// other than the one the admin changed it on.
+const moderationRulesTTL = time.Minute
+
+// ReloadModerationRules replaces the rules of the moderation filter with the
+// ones from the rules file, if configured, and the moderation_rules table.
+// Rules from the table take precedence over the file.
+func (config *ApiConfig) ReloadModerationRules(ctx context.Context) error {
+	setter, ok := config.Moderation.(moderation.RuleSetter)
+	if !ok {
+		return nil
+	}
+
+	rules := []moderation.Rule{}
+	if config.ModerationRulesFile != "" {
+		fileRules, err := moderation.LoadRulesFile(config.ModerationRulesFile)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, fileRules...)
+	}
+
+	dbRules, err := config.Db.ListModerationRules(ctx)
+	if err != nil {
+		return err
+	}
+	for _, rule := range dbRules {
+		rules = append(rules, moderation.Rule{Word: rule.Word, Action: moderation.Action(rule.Action)})
+	}
+
+	setter.SetRules(rules)
+	return nil
+}
+
+// WatchModerationRules reloads the moderation rules every minute until ctx is
+// done. Admin changes reload the rules right away, but only on the instance
+// that handled the request.
+func (config *ApiConfig) WatchModerationRules(ctx context.Context) {
+	ticker := time.NewTicker(moderationRulesTTL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := config.ReloadModerationRules(ctx)
+			if err != nil {
+				log.Printf("Error reloading moderation rules: %s", err)
+			}
+		}
+	}
+}
+
+// flagChirp records the words that got a chirp flagged for review.
+func flagChirp(ctx context.Context, q *database.Queries, chirpId uuid.UUID, result moderation.Result) error {
+	if !result.Flagged {
+		return nil
+	}
+	words := []string{}
+	for _, match := range result.Matches {
+		if match.Action == moderation.ActionFlag {
+			words = append(words, match.Word)
+		}
+	}
+	return q.CreateChirpFlags(ctx, database.CreateChirpFlagsParams{
+		ChirpID: chirpId,
+		Words:   words,
+	})
+}
+
+func (config *ApiConfig) GetModerationRulesHandler(rw http.ResponseWriter, req *http.Request) {
+	rules, err := config.Db.ListModerationRules(req.Context())
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	mappedRules := make([]models.ModerationRule, len(rules))
+	for i, rule := range rules {
+		mappedRules[i] = mapModerationRule(rule)
+	}
+	respond(rw, http.StatusOK, mappedRules)
+}
+
+func (config *ApiConfig) PutModerationRuleHandler(rw http.ResponseWriter, req *http.Request) {
+	word := req.PathValue("word")
+	if !moderation.IsWord(word) {
+		respondError(rw, http.StatusBadRequest, "A rule must be a single word")
+		return
+	}
+
+	type reqData struct {
+		Action string `json:"action"`
+	}
+	decoder := json.NewDecoder(req.Body)
+	params := reqData{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	action, err := moderation.ParseAction(params.Action)
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Action must be 'mask', 'reject' or 'flag'")
+		return
+	}
+
+	rule, err := config.Db.UpsertModerationRule(req.Context(), database.UpsertModerationRuleParams{
+		Word:   word,
+		Action: string(action),
+	})
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	err = config.ReloadModerationRules(req.Context())
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusOK, mapModerationRule(rule))
+}
+
+func (config *ApiConfig) DeleteModerationRuleHandler(rw http.ResponseWriter, req *http.Request) {
+	err := config.Db.DeleteModerationRule(req.Context(), req.PathValue("word"))
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	err = config.ReloadModerationRules(req.Context())
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusNoContent, nil)
+}
+
+func (config *ApiConfig) GetFlaggedChirpsHandler(rw http.ResponseWriter, req *http.Request) {
+	limit, cursor, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := database.ListFlaggedChirpsParams{PageSize: int32(limit + 1)}
+	if cursor != nil {
+		params.CursorTime = sql.NullTime{Time: cursor.Time, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+	}
+
+	rows, err := config.Db.ListFlaggedChirps(req.Context(), params)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	page := models.FlaggedChirpPage{}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[limit-1]
+		page.NextCursor = pageCursor{Time: last.FlaggedAt, ID: last.Chirp.ID}.encode()
+	}
+
+	chirps := make([]database.Chirp, len(rows))
+	for i, row := range rows {
+		chirps[i] = row.Chirp
+	}
+	view := config.chirpViewFor(req)
+	view.includeAuthors = true
+	mappedChirps, err := config.mapChirps(req.Context(), chirps, view)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	page.Chirps = make([]models.FlaggedChirp, len(rows))
+	for i, row := range rows {
+		page.Chirps[i] = models.FlaggedChirp{
+			Chirp:        mappedChirps[i],
+			FlaggedWords: row.Words,
+			FlaggedAt:    row.FlaggedAt,
+		}
+	}
+
+	respond(rw, http.StatusOK, page)
+}
+
+// DismissChirpFlagsHandler removes a chirp from the review queue once an admin
+// has looked at it.
+func (config *ApiConfig) DismissChirpFlagsHandler(rw http.ResponseWriter, req *http.Request) {
+	chirpId, err := uuid.Parse(req.PathValue("chirpId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid chirp ID")
+		return
+	}
+
+	err = config.Db.DeleteChirpFlags(req.Context(), chirpId)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusNoContent, nil)
+}
+
+func mapModerationRule(rule database.ModerationRule) models.ModerationRule {
+	return models.ModerationRule{
+		Word:      rule.Word,
+		Action:    rule.Action,
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+	}
+}
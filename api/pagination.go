@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const defaultPageSize = 20
+const maxPageSize = 100
+
+// pageCursor points at the last item of a page. It is handed to clients as
+// an opaque base64 string and is only meaningful to the endpoint that made it.
+// Listings ordered by time use Time, search results ordered by relevance use
+// Rank. Listings that can be sorted in several ways record the order in Sort.
+type pageCursor struct {
+	Time time.Time `json:"t,omitzero"`
+	Rank float32   `json:"r,omitempty"`
+	Sort string    `json:"s,omitempty"`
+	ID   uuid.UUID `json:"id"`
+}
+
+func (cursor pageCursor) encode() string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	cursor := pageCursor{}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, errors.New("invalid parameter 'cursor'")
+	}
+	err = json.Unmarshal(data, &cursor)
+	if err != nil || cursor.ID == uuid.Nil {
+		return pageCursor{}, errors.New("invalid parameter 'cursor'")
+	}
+	return cursor, nil
+}
+
+// parsePageParams reads the "limit" and "cursor" query parameters. The cursor
+// is nil when the client asks for the first page.
+func parsePageParams(query url.Values) (int, *pageCursor, error) {
+	limit := defaultPageSize
+	if s := query.Get("limit"); s != "" {
+		l, err := strconv.Atoi(s)
+		if err != nil || l < 1 || l > maxPageSize {
+			return 0, nil, errors.New("invalid parameter 'limit': must be a number between 1 and " + strconv.Itoa(maxPageSize))
+		}
+		limit = l
+	}
+
+	s := query.Get("cursor")
+	if s == "" {
+		return limit, nil, nil
+	}
+	cursor, err := decodeCursor(s)
+	if err != nil {
+		return 0, nil, err
+	}
+	return limit, &cursor, nil
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joac1144/bootdev-chirpy/internal/ratelimit"
+)
+
+// RateLimited limits how often a client can call next. Clients are told apart
+// by the user ID in their access token or, without a valid token, by their IP
+// address. Routes with the same name share their buckets.
+func (config *ApiConfig) RateLimited(name string, limit ratelimit.Limit, next http.HandlerFunc) http.HandlerFunc {
+	return config.rateLimited(limit, next, func(req *http.Request) string {
+		if userId, err := config.authenticate(req); err == nil {
+			return name + ":user:" + userId.String()
+		}
+		return name + ":ip:" + config.clientIP(req)
+	})
+}
+
+// RateLimitedByIP is RateLimited for routes that hand out tokens, like login
+// and refresh. Their clients are always told apart by IP address, since
+// sending a token of some other account must not buy a fresh bucket.
+func (config *ApiConfig) RateLimitedByIP(name string, limit ratelimit.Limit, next http.HandlerFunc) http.HandlerFunc {
+	return config.rateLimited(limit, next, func(req *http.Request) string {
+		return name + ":ip:" + config.clientIP(req)
+	})
+}
+
+func (config *ApiConfig) rateLimited(limit ratelimit.Limit, next http.HandlerFunc, key func(req *http.Request) string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if config.RateLimitStore == nil {
+			next(rw, req)
+			return
+		}
+
+		result, err := config.RateLimitStore.Take(req.Context(), key(req), limit)
+		if err != nil {
+			// A broken limiter should not take the API down with it.
+			log.Printf("Error taking rate limit token: %s", err)
+			next(rw, req)
+			return
+		}
+
+		rw.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		rw.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		rw.Header().Set("X-RateLimit-Reset", ceilSeconds(result.ResetAfter))
+		if !result.Allowed {
+			rw.Header().Set("Retry-After", ceilSeconds(result.RetryAfter))
+			respondError(rw, http.StatusTooManyRequests, "Too many requests")
+			return
+		}
+		next(rw, req)
+	}
+}
+
+// clientIP returns the IP address of the client. X-Forwarded-For is only
+// trusted when the server runs behind a proxy that sets it, and then only its
+// last entry: the proxy appends the address it saw, while anything before it
+// comes from the client and can be made up.
+func (config *ApiConfig) clientIP(req *http.Request) string {
+	if config.TrustProxy {
+		entries := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+		if ip := strings.TrimSpace(entries[len(entries)-1]); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -1,14 +1,28 @@
 package api
 
 import (
+	"database/sql"
+	"errors"
+	"log"
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/joac1144/bootdev-chirpy/internal/auth"
+	"github.com/joac1144/bootdev-chirpy/internal/database"
 )
 
 const RefreshPath string = "POST /api/refresh"
 
+const refreshTokenLifetime = 60 * 24 * time.Hour
+
+var errRefreshTokenReused = errors.New("refresh token has already been used")
+var errRefreshTokenInvalid = errors.New("refresh token is expired or revoked")
+
+// RefreshHandler exchanges a refresh token for a new access token and a new
+// refresh token. The presented token is revoked; presenting it again is taken
+// as a sign that it was stolen and revokes every token of its family.
 func (config *ApiConfig) RefreshHandler(rw http.ResponseWriter, req *http.Request) {
 	refreshToken, err := auth.GetBearerToken(req.Header)
 	if err != nil {
@@ -16,21 +30,101 @@ func (config *ApiConfig) RefreshHandler(rw http.ResponseWriter, req *http.Reques
 		return
 	}
 
-	user, err := config.Db.GetUserFromRefreshToken(req.Context(), refreshToken)
+	tokenHash := auth.HashToken(refreshToken)
+	var user database.User
+	var familyId uuid.UUID
+	newRefreshToken := ""
+	err = config.withTx(req.Context(), func(q *database.Queries) error {
+		token, err := q.GetRefreshToken(req.Context(), tokenHash)
+		if err != nil {
+			return err
+		}
+		familyId = token.FamilyID
+		if token.ReplacedBy.Valid {
+			return errRefreshTokenReused
+		}
+		if token.RevokedAt.Valid || !token.ExpiresAt.After(time.Now()) {
+			return errRefreshTokenInvalid
+		}
+
+		user, err = q.GetUserById(req.Context(), token.UserID)
+		if err != nil {
+			return err
+		}
+		if user.SuspendedAt.Valid {
+			return nil
+		}
+
+		newRefreshToken, err = config.issueRefreshToken(req, q, user.ID, familyId)
+		if err != nil {
+			return err
+		}
+		rotated, err := q.RotateRefreshToken(req.Context(), database.RotateRefreshTokenParams{
+			TokenHash:  tokenHash,
+			ReplacedBy: sql.NullString{String: auth.HashToken(newRefreshToken), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		// Another request rotated the token since it was read.
+		if rotated == 0 {
+			return errRefreshTokenReused
+		}
+		return nil
+	})
+	if errors.Is(err, errRefreshTokenReused) {
+		err = config.Db.RevokeRefreshTokenFamily(req.Context(), familyId)
+		if err != nil {
+			log.Printf("Failed to revoke refresh token family %s: %s", familyId, err)
+		}
+		respondError(rw, http.StatusUnauthorized, "Refresh token has already been used")
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, errRefreshTokenInvalid) {
+		respondError(rw, http.StatusUnauthorized, "Refresh token is invalid, expired or revoked")
+		return
+	}
 	if err != nil {
-		respondError(rw, http.StatusUnauthorized, "Failed to retrieve user from refresh token: "+err.Error())
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if user.SuspendedAt.Valid {
+		respondError(rw, http.StatusForbidden, "Your account is suspended")
 		return
 	}
 
-	newToken, err := auth.MakeJWT(user.ID, config.Secret, time.Hour)
+	newToken, err := config.Keys.MakeJWT(user.ID, time.Hour, auth.WithRole(user.Role), auth.ForAudience(accessTokenAudience))
 	if err != nil {
 		respondError(rw, http.StatusInternalServerError, "Failed to create new JWT token")
 		return
 	}
 
 	type response struct {
-		Token string `json:"token"`
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
 	}
 
-	respond(rw, http.StatusOK, response{Token: newToken})
+	respond(rw, http.StatusOK, response{Token: newToken, RefreshToken: newRefreshToken})
+}
+
+// issueRefreshToken creates a refresh token for userId in the given family.
+// Only its hash is stored; the token itself is returned to hand to the client.
+// The request's user agent and IP are kept to describe the session.
+func (config *ApiConfig) issueRefreshToken(req *http.Request, q *database.Queries, userId, familyId uuid.UUID) (string, error) {
+	refreshToken, err := auth.MakeRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	_, err = q.CreateRefreshToken(req.Context(), database.CreateRefreshTokenParams{
+		TokenHash: auth.HashToken(refreshToken),
+		UserID:    userId,
+		ExpiresAt: time.Now().Add(refreshTokenLifetime),
+		FamilyID:  familyId,
+		UserAgent: req.UserAgent(),
+		Ip:        config.clientIP(req),
+	})
+	if err != nil {
+		return "", err
+	}
+	return refreshToken, nil
 }
@@ -0,0 +1,377 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/joac1144/bootdev-chirpy/internal/auth"
+	"github.com/joac1144/bootdev-chirpy/internal/database"
+	"github.com/joac1144/bootdev-chirpy/models"
+)
+
+const ReportChirpPath string = "POST /api/chirps/{chirpId}/reports"
+const ReportUserPath string = "POST /api/users/{userId}/reports"
+const GetReportsPath string = "GET /admin/reports"
+const DismissReportPath string = "POST /admin/reports/{reportId}/dismiss"
+const HideReportedChirpPath string = "POST /admin/reports/{reportId}/hide"
+const SuspendReportedUserPath string = "POST /admin/reports/{reportId}/suspend"
+const UnsuspendUserPath string = "POST /admin/users/{userId}/unsuspend"
+const UnhideChirpPath string = "POST /admin/chirps/{chirpId}/unhide"
+
+const maxReportReasonLength = 500
+
+const (
+	reportStatusOpen      = "open"
+	reportStatusDismissed = "dismissed"
+	reportStatusActioned  = "actioned"
+)
+
+func (config *ApiConfig) ReportChirpHandler(rw http.ResponseWriter, req *http.Request) {
+	userId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+
+	chirpId, err := uuid.Parse(req.PathValue("chirpId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid chirp ID")
+		return
+	}
+
+	chirp, err := config.Db.GetChirpById(req.Context(), chirpId)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !viewAs(userId).canSee(chirp)) {
+		respondError(rw, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	config.createReport(rw, req, database.CreateReportParams{
+		ReporterID: userId,
+		ChirpID:    uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		UserID:     chirp.UserID,
+	})
+}
+
+func (config *ApiConfig) ReportUserHandler(rw http.ResponseWriter, req *http.Request) {
+	userId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+
+	reportedUserId, err := uuid.Parse(req.PathValue("userId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	_, err = config.Db.GetUserById(req.Context(), reportedUserId)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(rw, http.StatusNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	config.createReport(rw, req, database.CreateReportParams{
+		ReporterID: userId,
+		UserID:     reportedUserId,
+	})
+}
+
+// createReport reads the reason from the request body and stores the report.
+func (config *ApiConfig) createReport(rw http.ResponseWriter, req *http.Request, params database.CreateReportParams) {
+	type reqData struct {
+		Reason string `json:"reason"`
+	}
+	decoder := json.NewDecoder(req.Body)
+	data := reqData{}
+	err := decoder.Decode(&data)
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	params.Reason = strings.TrimSpace(data.Reason)
+	if params.Reason == "" {
+		respondError(rw, http.StatusBadRequest, "A reason is required")
+		return
+	}
+	if utf8.RuneCountInString(params.Reason) > maxReportReasonLength {
+		respondError(rw, http.StatusBadRequest, "Reason is too long")
+		return
+	}
+	if params.UserID == params.ReporterID {
+		respondError(rw, http.StatusBadRequest, "You cannot report yourself")
+		return
+	}
+
+	report, err := config.Db.CreateReport(req.Context(), params)
+	if isUniqueViolation(err) {
+		respondError(rw, http.StatusConflict, "You have already reported this")
+		return
+	}
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusCreated, mapReport(report))
+}
+
+// GetReportsHandler lists reports with the given status, oldest first, so the
+// queue is worked through in the order reports came in.
+func (config *ApiConfig) GetReportsHandler(rw http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	limit, cursor, err := parsePageParams(query)
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := database.ListReportsParams{
+		Status:   reportStatusOpen,
+		PageSize: int32(limit + 1),
+	}
+	switch status := query.Get("status"); status {
+	case "":
+	case reportStatusOpen, reportStatusDismissed, reportStatusActioned:
+		params.Status = status
+	default:
+		respondError(rw, http.StatusBadRequest, "invalid parameter 'status': must be 'open', 'dismissed' or 'actioned'")
+		return
+	}
+	if cursor != nil {
+		params.CursorTime = sql.NullTime{Time: cursor.Time, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+	}
+
+	reports, err := config.Db.ListReports(req.Context(), params)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	page := models.ReportPage{Reports: make([]models.Report, 0, len(reports))}
+	if len(reports) > limit {
+		reports = reports[:limit]
+		last := reports[limit-1]
+		page.NextCursor = pageCursor{Time: last.CreatedAt, ID: last.ID}.encode()
+	}
+	for _, report := range reports {
+		page.Reports = append(page.Reports, mapReport(report))
+	}
+
+	respond(rw, http.StatusOK, page)
+}
+
+func (config *ApiConfig) DismissReportHandler(rw http.ResponseWriter, req *http.Request) {
+	config.resolveReport(rw, req, func(q *database.Queries, report database.Report, adminId uuid.NullUUID) error {
+		_, err := q.ResolveReport(req.Context(), database.ResolveReportParams{
+			Status:     reportStatusDismissed,
+			ResolvedBy: adminId,
+			ID:         report.ID,
+		})
+		return err
+	})
+}
+
+// HideReportedChirpHandler hides the reported chirp and resolves every open
+// report about it.
+func (config *ApiConfig) HideReportedChirpHandler(rw http.ResponseWriter, req *http.Request) {
+	config.resolveReport(rw, req, func(q *database.Queries, report database.Report, adminId uuid.NullUUID) error {
+		if !report.ChirpID.Valid {
+			return errReportNotAboutChirp
+		}
+		err := q.HideChirp(req.Context(), report.ChirpID.UUID)
+		if err != nil {
+			return err
+		}
+		return q.ResolveChirpReports(req.Context(), database.ResolveChirpReportsParams{
+			Status:     reportStatusActioned,
+			ResolvedBy: adminId,
+			ChirpID:    report.ChirpID,
+		})
+	})
+}
+
+// SuspendReportedUserHandler suspends the reported user, or the author of
+// the reported chirp, and resolves every open report about them.
+func (config *ApiConfig) SuspendReportedUserHandler(rw http.ResponseWriter, req *http.Request) {
+	config.resolveReport(rw, req, func(q *database.Queries, report database.Report, adminId uuid.NullUUID) error {
+		claims, err := config.claims(req)
+		if err != nil {
+			return err
+		}
+		user, err := q.GetUserById(req.Context(), report.UserID)
+		if err != nil {
+			return err
+		}
+		if auth.HasRole(user.Role, claims.Role) {
+			return errUserNotOutranked
+		}
+		// Suspending also revokes the user's access tokens.
+		err = q.SuspendUser(req.Context(), report.UserID)
+		if err != nil {
+			return err
+		}
+		return q.ResolveUserReports(req.Context(), database.ResolveUserReportsParams{
+			Status:     reportStatusActioned,
+			ResolvedBy: adminId,
+			UserID:     report.UserID,
+		})
+	})
+}
+
+var errReportNotAboutChirp = errors.New("report is not about a chirp")
+
+// errUserNotOutranked keeps moderators from suspending each other, or an
+// admin, which they could not undo.
+var errUserNotOutranked = errors.New("user's role is at least your own")
+
+// resolveReport looks up the open report of an admin action and runs the
+// action in a transaction, responding with the resolved report.
+func (config *ApiConfig) resolveReport(rw http.ResponseWriter, req *http.Request, action func(q *database.Queries, report database.Report, adminId uuid.NullUUID) error) {
+	adminId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+
+	reportId, err := uuid.Parse(req.PathValue("reportId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid report ID")
+		return
+	}
+
+	report, err := config.Db.GetReportById(req.Context(), reportId)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(rw, http.StatusNotFound, "Report not found")
+		return
+	}
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if report.Status != reportStatusOpen {
+		respondError(rw, http.StatusConflict, "Report has already been resolved")
+		return
+	}
+
+	err = config.withTx(req.Context(), func(q *database.Queries) error {
+		return action(q, report, uuid.NullUUID{UUID: adminId, Valid: true})
+	})
+	if errors.Is(err, errReportNotAboutChirp) {
+		respondError(rw, http.StatusBadRequest, "Report is not about a chirp")
+		return
+	}
+	if errors.Is(err, errUserNotOutranked) {
+		respondError(rw, http.StatusForbidden, "You cannot act on a user whose role is at least your own")
+		return
+	}
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// Actions may revoke the user's access tokens, which only takes effect
+	// once committed.
+	config.tokenRevocations.forget(report.UserID)
+
+	report, err = config.Db.GetReportById(req.Context(), reportId)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respond(rw, http.StatusOK, mapReport(report))
+}
+
+// UnsuspendUserHandler lifts the suspension of a user. Like suspending, it
+// only works on users whose role is below the caller's.
+func (config *ApiConfig) UnsuspendUserHandler(rw http.ResponseWriter, req *http.Request) {
+	userId, err := uuid.Parse(req.PathValue("userId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	claims, err := config.claims(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+
+	user, err := config.Db.GetUserById(req.Context(), userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(rw, http.StatusNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if auth.HasRole(user.Role, claims.Role) {
+		respondError(rw, http.StatusForbidden, "You cannot act on a user whose role is at least your own")
+		return
+	}
+
+	user, err = config.Db.UnsuspendUser(req.Context(), userId)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusOK, mapUser(user))
+}
+
+// UnhideChirpHandler makes a chirp hidden by moderators visible again.
+func (config *ApiConfig) UnhideChirpHandler(rw http.ResponseWriter, req *http.Request) {
+	chirpId, err := uuid.Parse(req.PathValue("chirpId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid chirp ID")
+		return
+	}
+
+	unhidden, err := config.Db.UnhideChirp(req.Context(), chirpId)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if unhidden == 0 {
+		respondError(rw, http.StatusNotFound, "Chirp not found")
+		return
+	}
+
+	respond(rw, http.StatusNoContent, nil)
+}
+
+func mapReport(report database.Report) models.Report {
+	mapped := models.Report{
+		ID:         report.ID,
+		ReporterID: report.ReporterID,
+		UserID:     report.UserID,
+		Reason:     report.Reason,
+		Status:     report.Status,
+		CreatedAt:  report.CreatedAt,
+	}
+	if report.ChirpID.Valid {
+		mapped.ChirpID = &report.ChirpID.UUID
+	}
+	if report.ResolvedAt.Valid {
+		mapped.ResolvedAt = &report.ResolvedAt.Time
+	}
+	if report.ResolvedBy.Valid {
+		mapped.ResolvedBy = &report.ResolvedBy.UUID
+	}
+	return mapped
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// tokenRevocationTTL bounds how long a cached tokens_valid_after is trusted.
+// Revocations made by this process apply at once; those made by other
+// instances apply within the TTL.
+const tokenRevocationTTL = 30 * time.Second
+
+const maxTokenRevocationEntries = 10000
+
+var errTokenRevoked = errors.New("token has been revoked")
+
+// errTokenCheckFailed means a token could not be checked against the
+// database. That is a server error, not something wrong with the token.
+var errTokenCheckFailed = errors.New("token could not be checked")
+
+// tokenRevocations caches each user's tokens_valid_after so that checking an
+// access token does not cost a query per request. The zero value is ready to
+// use.
+type tokenRevocations struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]tokenRevocation
+}
+
+type tokenRevocation struct {
+	validAfter sql.NullTime
+	fetchedAt  time.Time
+}
+
+// validAfter returns the time before which the user's access tokens are
+// rejected, loading it with load when it is not cached.
+func (revocations *tokenRevocations) validAfter(ctx context.Context, userId uuid.UUID, load func(context.Context, uuid.UUID) (sql.NullTime, error)) (sql.NullTime, error) {
+	now := time.Now()
+	revocations.mu.Lock()
+	entry, ok := revocations.entries[userId]
+	revocations.mu.Unlock()
+	if ok && now.Sub(entry.fetchedAt) < tokenRevocationTTL {
+		return entry.validAfter, nil
+	}
+
+	validAfter, err := load(ctx, userId)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+
+	revocations.mu.Lock()
+	defer revocations.mu.Unlock()
+	if revocations.entries == nil || len(revocations.entries) >= maxTokenRevocationEntries {
+		revocations.entries = map[uuid.UUID]tokenRevocation{}
+	}
+	revocations.entries[userId] = tokenRevocation{validAfter: validAfter, fetchedAt: now}
+	return validAfter, nil
+}
+
+// forget drops the cached entry of a user whose tokens were just revoked.
+func (revocations *tokenRevocations) forget(userId uuid.UUID) {
+	revocations.mu.Lock()
+	defer revocations.mu.Unlock()
+	delete(revocations.entries, userId)
+}
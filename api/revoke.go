@@ -15,7 +15,7 @@ func (config *ApiConfig) RevokeHandler(rw http.ResponseWriter, req *http.Request
 		return
 	}
 
-	err = config.Db.RevokeRefreshToken(req.Context(), refreshToken)
+	err = config.Db.RevokeRefreshToken(req.Context(), auth.HashToken(refreshToken))
 	if err != nil {
 		respondError(rw, http.StatusInternalServerError, "Failed to revoke refresh token: "+err.Error())
 		return
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"database/sql"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/joac1144/bootdev-chirpy/internal/database"
+	"github.com/joac1144/bootdev-chirpy/models"
+)
+
+const SearchChirpsPath string = "GET /api/chirps/search"
+
+// ts_headline does not escape the chirp body, so matches are delimited with
+// control characters that are swapped for <mark> tags after escaping.
+const headlineStartSel = "\x02"
+const headlineStopSel = "\x03"
+const headlineOptions = "StartSel=" + headlineStartSel + ", StopSel=" + headlineStopSel + ", MaxFragments=2, MinWords=5, MaxWords=20"
+
+const maxSearchQueryLength = 200
+
+func (config *ApiConfig) SearchChirpsHandler(rw http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" {
+		respondError(rw, http.StatusBadRequest, "invalid parameter 'q': must not be empty")
+		return
+	}
+	if len(q) > maxSearchQueryLength {
+		respondError(rw, http.StatusBadRequest, "invalid parameter 'q': too long")
+		return
+	}
+
+	limit, cursor, err := parsePageParams(query)
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	view := config.chirpViewFor(req)
+	params := database.SearchChirpsParams{
+		HeadlineOptions: headlineOptions,
+		Query:           q,
+		ViewerID:        view.viewerId,
+		PageSize:        int32(limit + 1),
+	}
+	if cursor != nil {
+		params.CursorRank = sql.NullFloat64{Float64: float64(cursor.Rank), Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+	}
+
+	results, err := config.Db.SearchChirps(req.Context(), params)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	page := models.ChirpSearchPage{}
+	if len(results) > limit {
+		results = results[:limit]
+		last := results[limit-1]
+		page.NextCursor = pageCursor{Rank: last.Rank, ID: last.Chirp.ID}.encode()
+	}
+
+	chirps := make([]database.Chirp, len(results))
+	for i, result := range results {
+		chirps[i] = result.Chirp
+	}
+	mappedChirps, err := config.mapChirps(req.Context(), chirps, view)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	page.Results = make([]models.ChirpSearchResult, len(results))
+	for i, result := range results {
+		page.Results[i] = models.ChirpSearchResult{
+			Chirp:    mappedChirps[i],
+			Rank:     result.Rank,
+			Headline: highlight(result.Headline),
+		}
+	}
+
+	respond(rw, http.StatusOK, page)
+}
+
+func highlight(headline string) string {
+	escaped := html.EscapeString(headline)
+	escaped = strings.ReplaceAll(escaped, headlineStartSel, "<mark>")
+	return strings.ReplaceAll(escaped, headlineStopSel, "</mark>")
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/joac1144/bootdev-chirpy/internal/database"
+	"github.com/joac1144/bootdev-chirpy/models"
+)
+
+const GetSessionsPath string = "GET /api/sessions"
+const RevokeSessionPath string = "DELETE /api/sessions/{sessionId}"
+const RevokeAllSessionsPath string = "POST /api/sessions/revoke-all"
+
+// GetSessionsHandler lists the caller's active sessions. A session is the
+// family of refresh tokens started by a login, identified by the family ID so
+// the tokens themselves never leave the server again.
+func (config *ApiConfig) GetSessionsHandler(rw http.ResponseWriter, req *http.Request) {
+	userId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+
+	sessions, err := config.Db.ListSessions(req.Context(), userId)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	mapped := make([]models.Session, len(sessions))
+	for i, session := range sessions {
+		mapped[i] = models.Session{
+			ID:         session.FamilyID,
+			CreatedAt:  session.StartedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			UserAgent:  session.UserAgent,
+			IP:         session.Ip,
+		}
+	}
+	respond(rw, http.StatusOK, mapped)
+}
+
+func (config *ApiConfig) RevokeSessionHandler(rw http.ResponseWriter, req *http.Request) {
+	sessionId, err := uuid.Parse(req.PathValue("sessionId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	userId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+
+	revoked, err := config.Db.RevokeSession(req.Context(), database.RevokeSessionParams{
+		FamilyID: sessionId,
+		UserID:   userId,
+	})
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if revoked == 0 {
+		respondError(rw, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	respond(rw, http.StatusNoContent, nil)
+}
+
+// RevokeAllSessionsHandler logs the caller out everywhere by revoking all of
+// their refresh tokens and the access tokens issued so far.
+func (config *ApiConfig) RevokeAllSessionsHandler(rw http.ResponseWriter, req *http.Request) {
+	userId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+
+	err = config.withTx(req.Context(), func(q *database.Queries) error {
+		err := q.RevokeUserSessions(req.Context(), userId)
+		if err != nil {
+			return err
+		}
+		return q.RevokeAccessTokens(req.Context(), userId)
+	})
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	config.tokenRevocations.forget(userId)
+
+	respond(rw, http.StatusNoContent, nil)
+}
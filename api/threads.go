@@ -0,0 +1,125 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/joac1144/bootdev-chirpy/internal/database"
+	"github.com/joac1144/bootdev-chirpy/models"
+)
+
+const GetChirpThreadPath string = "GET /api/chirps/{chirpId}/thread"
+
+const defaultThreadDepth = 3
+const maxThreadDepth = 10
+const maxThreadAncestors = 50
+const maxThreadReplies = 500
+
+func (config *ApiConfig) GetChirpThreadHandler(rw http.ResponseWriter, req *http.Request) {
+	chirpId, err := uuid.Parse(req.PathValue("chirpId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid chirp ID")
+		return
+	}
+
+	depth := defaultThreadDepth
+	if s := req.URL.Query().Get("depth"); s != "" {
+		depth, err = strconv.Atoi(s)
+		if err != nil || depth < 0 || depth > maxThreadDepth {
+			respondError(rw, http.StatusBadRequest, "invalid parameter 'depth': must be a number between 0 and "+strconv.Itoa(maxThreadDepth))
+			return
+		}
+	}
+
+	view := config.chirpViewFor(req)
+	chirp, err := config.Db.GetChirpById(req.Context(), chirpId)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !view.canSee(chirp)) {
+		respondError(rw, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ancestors, err := config.Db.ListChirpAncestors(req.Context(), database.ListChirpAncestorsParams{
+		ChirpID:  chirpId,
+		MaxDepth: maxThreadAncestors,
+	})
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	replies := []database.ListChirpRepliesRow{}
+	if depth > 0 {
+		replies, err = config.Db.ListChirpReplies(req.Context(), database.ListChirpRepliesParams{
+			ChirpID:    chirpId,
+			MaxDepth:   int32(depth),
+			MaxReplies: maxThreadReplies,
+		})
+		if err != nil {
+			respondError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	blocked, err := config.blockedUserIds(req.Context(), view.viewerId)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if blocked[chirp.UserID] {
+		respondError(rw, http.StatusNotFound, "Chirp not found")
+		return
+	}
+
+	// Hidden chirps and chirps of blocked users are left out; replies to a
+	// left out reply go with it.
+	chirps := make([]database.Chirp, 0, len(ancestors)+1+len(replies))
+	for _, ancestor := range ancestors {
+		if view.canSee(ancestor.Chirp) && !blocked[ancestor.Chirp.UserID] {
+			chirps = append(chirps, ancestor.Chirp)
+		}
+	}
+	ancestorCount := len(chirps)
+	chirps = append(chirps, chirp)
+	for _, reply := range replies {
+		if view.canSee(reply.Chirp) && !blocked[reply.Chirp.UserID] {
+			chirps = append(chirps, reply.Chirp)
+		}
+	}
+
+	mappedChirps, err := config.mapChirps(req.Context(), chirps, view)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	thread := models.ChirpThread{
+		Ancestors: mappedChirps[:ancestorCount],
+		Chirp:     mappedChirps[ancestorCount],
+	}
+
+	children := map[uuid.UUID][]models.Chirp{}
+	for _, reply := range mappedChirps[ancestorCount+1:] {
+		children[*reply.InReplyTo] = append(children[*reply.InReplyTo], reply)
+	}
+	thread.Replies = buildReplyTree(chirpId, children)
+
+	respond(rw, http.StatusOK, thread)
+}
+
+func buildReplyTree(parentId uuid.UUID, children map[uuid.UUID][]models.Chirp) []models.ChirpReply {
+	tree := make([]models.ChirpReply, len(children[parentId]))
+	for i, child := range children[parentId] {
+		tree[i] = models.ChirpReply{
+			Chirp:   child,
+			Replies: buildReplyTree(child.ID, children),
+		}
+	}
+	return tree
+}
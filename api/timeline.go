@@ -0,0 +1,56 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/joac1144/bootdev-chirpy/internal/database"
+	"github.com/joac1144/bootdev-chirpy/models"
+)
+
+const GetTimelinePath string = "GET /api/timeline"
+
+func (config *ApiConfig) GetTimelineHandler(rw http.ResponseWriter, req *http.Request) {
+	userId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+
+	limit, cursor, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := database.ListTimelineChirpsParams{
+		UserID:   userId,
+		PageSize: int32(limit + 1),
+	}
+	if cursor != nil {
+		params.CursorTime = sql.NullTime{Time: cursor.Time, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+	}
+
+	chirps, err := config.Db.ListTimelineChirps(req.Context(), params)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	page := models.ChirpPage{}
+	if len(chirps) > limit {
+		chirps = chirps[:limit]
+		last := chirps[limit-1]
+		page.NextCursor = pageCursor{Time: last.CreatedAt, ID: last.ID}.encode()
+	}
+
+	page.Chirps, err = config.mapChirps(req.Context(), chirps, config.chirpViewFor(req))
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusOK, page)
+}
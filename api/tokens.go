@@ -0,0 +1,228 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/joac1144/bootdev-chirpy/internal/auth"
+	"github.com/joac1144/bootdev-chirpy/internal/database"
+	"github.com/joac1144/bootdev-chirpy/models"
+)
+
+const CreateTokenPath string = "POST /api/tokens"
+const GetTokensPath string = "GET /api/tokens"
+const RevokeTokenPath string = "DELETE /api/tokens/{tokenId}"
+
+const maxTokenNameLength = 100
+const maxTokenLifetimeDays = 365
+
+var errPersonalAccessTokenNotAllowed = errors.New("personal access tokens cannot be used here")
+var errPersonalAccessTokenInvalid = errors.New("personal access token is invalid, expired or revoked")
+
+type claimsKey struct{}
+
+// RequireScope accepts personal access tokens with the given scope as well as
+// access tokens from a login. The claims are passed on in the request context,
+// so handlers authenticate as usual.
+func (config *ApiConfig) RequireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		claims, err := config.scopedClaims(req)
+		if err != nil {
+			respondUnauthorized(rw, err)
+			return
+		}
+		if !claims.HasScope(scope) {
+			type resError struct {
+				Error string `json:"error"`
+				Code  string `json:"code"`
+			}
+			rw.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+			respond(rw, http.StatusForbidden, resError{
+				Error: "Token lacks the '" + scope + "' scope",
+				Code:  "insufficient_scope",
+			})
+			return
+		}
+		next(rw, req.WithContext(context.WithValue(req.Context(), claimsKey{}, claims)))
+	}
+}
+
+// OptionalScope is RequireScope for routes that also serve anonymous clients.
+// Requests without an Authorization header go straight to next; requests with
+// one must carry a token with the scope.
+func (config *ApiConfig) OptionalScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	requireScope := config.RequireScope(scope, next)
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") == "" {
+			next(rw, req)
+			return
+		}
+		requireScope(rw, req)
+	}
+}
+
+func (config *ApiConfig) scopedClaims(req *http.Request) (*auth.Claims, error) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		return nil, err
+	}
+	if !auth.IsPersonalAccessToken(token) {
+		return config.claims(req)
+	}
+
+	pat, err := config.Db.UsePersonalAccessToken(req.Context(), auth.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errPersonalAccessTokenInvalid
+	}
+	if err != nil {
+		log.Printf("Error checking personal access token: %s", err)
+		return nil, errTokenCheckFailed
+	}
+	return auth.NewPersonalAccessTokenClaims(pat.UserID, pat.ID, pat.Scopes), nil
+}
+
+// CreateTokenHandler creates a personal access token for bots and scripts.
+// The token is only shown in the response; afterwards it is known by its ID.
+func (config *ApiConfig) CreateTokenHandler(rw http.ResponseWriter, req *http.Request) {
+	type reqData struct {
+		Name          string   `json:"name"`
+		Scopes        []string `json:"scopes"`
+		ExpiresInDays int      `json:"expires_in_days"`
+	}
+
+	userId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := reqData{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if params.Name == "" || utf8.RuneCountInString(params.Name) > maxTokenNameLength {
+		respondError(rw, http.StatusBadRequest, "Token name must be 1 to 100 characters")
+		return
+	}
+	if len(params.Scopes) == 0 {
+		respondError(rw, http.StatusBadRequest, "Token needs at least one scope")
+		return
+	}
+	for _, scope := range params.Scopes {
+		if !auth.IsValidScope(scope) {
+			respondError(rw, http.StatusBadRequest, "Unknown scope '"+scope+"'")
+			return
+		}
+	}
+	if params.ExpiresInDays < 0 || params.ExpiresInDays > maxTokenLifetimeDays {
+		respondError(rw, http.StatusBadRequest, "Token lifetime must be between 1 and 365 days, or 0 for no expiry")
+		return
+	}
+	scopes := slices.Compact(slices.Sorted(slices.Values(params.Scopes)))
+
+	expiresAt := sql.NullTime{}
+	if params.ExpiresInDays > 0 {
+		expiresAt = sql.NullTime{Time: time.Now().AddDate(0, 0, params.ExpiresInDays), Valid: true}
+	}
+
+	token, err := auth.MakePersonalAccessToken()
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, "Failed to create token")
+		return
+	}
+
+	pat, err := config.Db.CreatePersonalAccessToken(req.Context(), database.CreatePersonalAccessTokenParams{
+		UserID:    userId,
+		Name:      params.Name,
+		TokenHash: auth.HashToken(token),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(rw, http.StatusCreated, models.NewPersonalAccessToken{
+		PersonalAccessToken: mapPersonalAccessToken(pat),
+		Token:               token,
+	})
+}
+
+func (config *ApiConfig) GetTokensHandler(rw http.ResponseWriter, req *http.Request) {
+	userId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+
+	pats, err := config.Db.ListPersonalAccessTokens(req.Context(), userId)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	mapped := make([]models.PersonalAccessToken, len(pats))
+	for i, pat := range pats {
+		mapped[i] = mapPersonalAccessToken(pat)
+	}
+	respond(rw, http.StatusOK, mapped)
+}
+
+func (config *ApiConfig) RevokeTokenHandler(rw http.ResponseWriter, req *http.Request) {
+	tokenId, err := uuid.Parse(req.PathValue("tokenId"))
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid token ID")
+		return
+	}
+
+	userId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+
+	revoked, err := config.Db.RevokePersonalAccessToken(req.Context(), database.RevokePersonalAccessTokenParams{
+		ID:     tokenId,
+		UserID: userId,
+	})
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if revoked == 0 {
+		respondError(rw, http.StatusNotFound, "Token not found")
+		return
+	}
+
+	respond(rw, http.StatusNoContent, nil)
+}
+
+func mapPersonalAccessToken(pat database.PersonalAccessToken) models.PersonalAccessToken {
+	mapped := models.PersonalAccessToken{
+		ID:        pat.ID,
+		Name:      pat.Name,
+		Scopes:    pat.Scopes,
+		CreatedAt: pat.CreatedAt,
+	}
+	if pat.LastUsedAt.Valid {
+		mapped.LastUsedAt = &pat.LastUsedAt.Time
+	}
+	if pat.ExpiresAt.Valid {
+		mapped.ExpiresAt = &pat.ExpiresAt.Time
+	}
+	return mapped
+}
@@ -1,8 +1,15 @@
 package api
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/mail"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
 
 	"github.com/joac1144/bootdev-chirpy/internal/auth"
 	"github.com/joac1144/bootdev-chirpy/internal/database"
@@ -11,11 +18,19 @@ import (
 
 const CreateUserPath string = "POST /api/users"
 const UpdateUserPath string = "PUT /api/users"
+const PatchUserPath string = "PATCH /api/users"
+const GetUserPath string = "GET /api/users/{idOrUsername}"
+
+const maxDisplayNameLength = 50
+const maxBioLength = 160
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{3,30}$`)
 
 func (config *ApiConfig) CreateUserHandler(rw http.ResponseWriter, req *http.Request) {
 	type reqData struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Email    string  `json:"email"`
+		Password string  `json:"password"`
+		Username *string `json:"username"`
 	}
 
 	decoder := json.NewDecoder(req.Body)
@@ -26,6 +41,15 @@ func (config *ApiConfig) CreateUserHandler(rw http.ResponseWriter, req *http.Req
 		return
 	}
 
+	if !isValidEmail(params.Email) {
+		respondError(rw, http.StatusBadRequest, "Invalid email address")
+		return
+	}
+	if errMsg := validateProfileFields(params.Username, nil, nil); errMsg != "" {
+		respondError(rw, http.StatusBadRequest, errMsg)
+		return
+	}
+
 	hashedPassword, err := auth.HashPassword(params.Password)
 	if err != nil {
 		respondError(rw, http.StatusInternalServerError, "Failed to hash password")
@@ -35,27 +59,38 @@ func (config *ApiConfig) CreateUserHandler(rw http.ResponseWriter, req *http.Req
 	user, err := config.Db.CreateUser(req.Context(), database.CreateUserParams{
 		Email:          params.Email,
 		HashedPassword: hashedPassword,
+		Username:       nullString(params.Username),
 	})
+	if isUniqueViolation(err) {
+		respondError(rw, http.StatusConflict, "Email or username is already taken")
+		return
+	}
 	if err != nil {
 		respondError(rw, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	mappedUser := models.User{
-		ID:          user.ID,
-		CreatedAt:   user.CreatedAt,
-		UpdatedAt:   user.UpdatedAt,
-		Email:       user.Email,
-		IsChirpyRed: user.IsChirpyRed,
+	if config.AdminEmail != "" && user.Email == config.AdminEmail {
+		updated, err := config.Db.BootstrapAdmin(req.Context(), user.Email)
+		if err != nil {
+			respondError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if updated > 0 {
+			user.Role = auth.RoleAdmin
+		}
 	}
 
-	respond(rw, http.StatusCreated, mappedUser)
+	respond(rw, http.StatusCreated, mapUser(user))
 }
 
 func (config *ApiConfig) UpdateUserHandler(rw http.ResponseWriter, req *http.Request) {
 	type request struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Email       string  `json:"email"`
+		Password    string  `json:"password"`
+		Username    *string `json:"username"`
+		DisplayName *string `json:"display_name"`
+		Bio         *string `json:"bio"`
 	}
 	decoder := json.NewDecoder(req.Body)
 	params := request{}
@@ -65,17 +100,32 @@ func (config *ApiConfig) UpdateUserHandler(rw http.ResponseWriter, req *http.Req
 		return
 	}
 
-	accessToken, err := auth.GetBearerToken(req.Header)
+	userId, err := config.authenticate(req)
 	if err != nil {
-		respondError(rw, http.StatusUnauthorized, "Unauthorized")
+		respondUnauthorized(rw, err)
 		return
 	}
 
-	userId, err := auth.ValidateJWT(accessToken, config.Secret)
-	if err != nil {
-		respondError(rw, http.StatusUnauthorized, "Invalid token")
+	if params.Password == "" {
+		respondError(rw, http.StatusBadRequest, "Password is required")
+		return
+	}
+	if !isValidEmail(params.Email) {
+		respondError(rw, http.StatusBadRequest, "Invalid email address")
 		return
 	}
+	if errMsg := validateProfileFields(params.Username, params.DisplayName, params.Bio); errMsg != "" {
+		respondError(rw, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	updateParams := database.UpdateUserParams{
+		ID:          userId,
+		Email:       params.Email,
+		Username:    nullString(params.Username),
+		DisplayName: nullString(params.DisplayName),
+		Bio:         nullString(params.Bio),
+	}
 
 	newHashedPassword, err := auth.HashPassword(params.Password)
 	if err != nil {
@@ -83,21 +133,259 @@ func (config *ApiConfig) UpdateUserHandler(rw http.ResponseWriter, req *http.Req
 		return
 	}
 
-	updatedUser, err := config.Db.UpdateUser(req.Context(), database.UpdateUserParams{
-		ID:             userId,
-		Email:          params.Email,
-		HashedPassword: newHashedPassword,
+	updateParams.HashedPassword = newHashedPassword
+
+	user, err := config.Db.GetUserById(req.Context(), userId)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	passwordChanged := auth.CheckPasswordHash(params.Password, user.HashedPassword) != nil
+
+	var updatedUser database.User
+	err = config.withTx(req.Context(), func(q *database.Queries) error {
+		var err error
+		updatedUser, err = q.UpdateUser(req.Context(), updateParams)
+		if err != nil {
+			return err
+		}
+		if !passwordChanged {
+			return nil
+		}
+		err = q.RevokeUserSessions(req.Context(), userId)
+		if err != nil {
+			return err
+		}
+		return q.RevokeAccessTokens(req.Context(), userId)
 	})
+	if isUniqueViolation(err) {
+		respondError(rw, http.StatusConflict, "Email or username is already taken")
+		return
+	}
 	if err != nil {
 		respondError(rw, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	respond(rw, http.StatusOK, models.User{
-		ID:          updatedUser.ID,
-		CreatedAt:   updatedUser.CreatedAt,
-		UpdatedAt:   updatedUser.UpdatedAt,
-		Email:       updatedUser.Email,
-		IsChirpyRed: updatedUser.IsChirpyRed,
+	if passwordChanged {
+		config.tokenRevocations.forget(userId)
+	}
+
+	respond(rw, http.StatusOK, mapUser(updatedUser))
+}
+
+// PatchUserHandler updates only the fields present in the request. Changing
+// the email or password requires the current password.
+func (config *ApiConfig) PatchUserHandler(rw http.ResponseWriter, req *http.Request) {
+	type request struct {
+		Email           *string `json:"email"`
+		Password        *string `json:"password"`
+		CurrentPassword string  `json:"current_password"`
+		Username        *string `json:"username"`
+		DisplayName     *string `json:"display_name"`
+		Bio             *string `json:"bio"`
+	}
+	decoder := json.NewDecoder(req.Body)
+	params := request{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondError(rw, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userId, err := config.authenticate(req)
+	if err != nil {
+		respondUnauthorized(rw, err)
+		return
+	}
+
+	if params.Email != nil && !isValidEmail(*params.Email) {
+		respondError(rw, http.StatusBadRequest, "Invalid email address")
+		return
+	}
+	if params.Password != nil && *params.Password == "" {
+		respondError(rw, http.StatusBadRequest, "Password cannot be empty")
+		return
+	}
+	if errMsg := validateProfileFields(params.Username, params.DisplayName, params.Bio); errMsg != "" {
+		respondError(rw, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	user, err := config.Db.GetUserById(req.Context(), userId)
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if params.Email != nil || params.Password != nil {
+		err = auth.CheckPasswordHash(params.CurrentPassword, user.HashedPassword)
+		if err != nil {
+			respondError(rw, http.StatusForbidden, "Current password is incorrect")
+			return
+		}
+	}
+
+	newHashedPassword := ""
+	if params.Password != nil {
+		newHashedPassword, err = auth.HashPassword(*params.Password)
+		if err != nil {
+			respondError(rw, http.StatusInternalServerError, "Failed to hash new password")
+			return
+		}
+	}
+
+	err = config.withTx(req.Context(), func(q *database.Queries) error {
+		var err error
+		if params.Email != nil {
+			user, err = q.UpdateUserEmail(req.Context(), database.UpdateUserEmailParams{
+				Email: *params.Email,
+				ID:    userId,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if params.Password != nil {
+			user, err = q.UpdateUserPassword(req.Context(), database.UpdateUserPasswordParams{
+				HashedPassword: newHashedPassword,
+				ID:             userId,
+			})
+			if err != nil {
+				return err
+			}
+			// Sessions and access tokens from before the change stop
+			// working.
+			err = q.RevokeUserSessions(req.Context(), userId)
+			if err != nil {
+				return err
+			}
+			err = q.RevokeAccessTokens(req.Context(), userId)
+			if err != nil {
+				return err
+			}
+		}
+		if params.Username != nil || params.DisplayName != nil || params.Bio != nil {
+			user, err = q.UpdateUserProfile(req.Context(), database.UpdateUserProfileParams{
+				Username:    nullString(params.Username),
+				DisplayName: nullString(params.DisplayName),
+				Bio:         nullString(params.Bio),
+				ID:          userId,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 	})
+	if isUniqueViolation(err) {
+		respondError(rw, http.StatusConflict, "Email or username is already taken")
+		return
+	}
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if params.Password != nil {
+		config.tokenRevocations.forget(userId)
+	}
+
+	respond(rw, http.StatusOK, mapUser(user))
+}
+
+func (config *ApiConfig) GetUserHandler(rw http.ResponseWriter, req *http.Request) {
+	idOrUsername := req.PathValue("idOrUsername")
+
+	var user database.User
+	userId, err := uuid.Parse(idOrUsername)
+	if err == nil {
+		user, err = config.Db.GetUserById(req.Context(), userId)
+	} else {
+		user, err = config.Db.GetUserByUsername(req.Context(), idOrUsername)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(rw, http.StatusNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		respondError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	viewerId := config.viewerId(req)
+	if viewerId.Valid {
+		blocked, err := config.isBlocked(req.Context(), viewerId.UUID, user.ID)
+		if err != nil {
+			respondError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if blocked {
+			respondError(rw, http.StatusNotFound, "User not found")
+			return
+		}
+	}
+
+	profile := mapProfile(user)
+	if viewerId.Valid && viewerId.UUID == user.ID {
+		profile.Email = user.Email
+	}
+	respond(rw, http.StatusOK, profile)
+}
+
+func isValidEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
+
+// validateProfileFields checks the optional profile fields of a request and
+// returns an error message for the first invalid one.
+func validateProfileFields(username, displayName, bio *string) string {
+	if username != nil && !usernamePattern.MatchString(*username) {
+		return "Username must be 3 to 30 letters, digits or underscores"
+	}
+	if displayName != nil && utf8.RuneCountInString(*displayName) > maxDisplayNameLength {
+		return "Display name is too long"
+	}
+	if bio != nil && utf8.RuneCountInString(*bio) > maxBioLength {
+		return "Bio is too long"
+	}
+	return ""
+}
+
+func nullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+func mapUser(user database.User) models.User {
+	mapped := models.User{
+		ID:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+		Email:       user.Email,
+		IsChirpyRed: user.IsChirpyRed,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		Role:        user.Role,
+	}
+	if user.Username.Valid {
+		mapped.Username = &user.Username.String
+	}
+	return mapped
+}
+
+func mapProfile(user database.User) models.Profile {
+	profile := models.Profile{
+		ID:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		IsChirpyRed: user.IsChirpyRed,
+	}
+	if user.Username.Valid {
+		profile.Username = &user.Username.String
+	}
+	return profile
 }
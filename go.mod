@@ -2,11 +2,11 @@ module github.com/joac1144/bootdev-chirpy
 
 go 1.24.2
 
-require golang.org/x/crypto v0.39.0
-
 require (
-	github.com/golang-jwt/jwt/v5 v5.2.2 // indirect
-	github.com/google/uuid v1.6.0 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
-	github.com/lib/pq v1.10.9 // indirect
+	github.com/golang-jwt/jwt/v5 v5.2.2
+	github.com/google/uuid v1.6.0
+	github.com/joho/godotenv v1.5.1
+	github.com/lib/pq v1.10.9
+	golang.org/x/crypto v0.39.0
+	golang.org/x/text v0.26.0
 )
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"net/http"
@@ -30,35 +31,69 @@ func CheckPasswordHash(password, hash string) error {
 	return nil
 }
 
-func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
-		Issuer:    "chirpy",
-		IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
-		ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(expiresIn)),
-		Subject:   userID.String(),
-	})
+// Claims are the claims of the access tokens Chirpy issues.
+type Claims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role,omitempty"`
+	// Scopes limits what a personal access token may be used for. It is nil
+	// for access tokens from a login.
+	Scopes []string `json:"-"`
+}
 
-	signed, err := token.SignedString([]byte(tokenSecret))
-	if err != nil {
-		return "", err
+// UserID returns the ID of the user the token was issued to.
+func (claims *Claims) UserID() (uuid.UUID, error) {
+	return uuid.Parse(claims.Subject)
+}
+
+// IssuedBefore reports whether the token was issued before t. Issue times
+// only have second precision, so a token issued in the same second as t
+// counts as issued after it; tokens without an issue time are always before.
+func (claims *Claims) IssuedBefore(t time.Time) bool {
+	if claims.IssuedAt == nil {
+		return true
 	}
-	return signed, nil
+	return claims.IssuedAt.Before(t.Truncate(time.Second))
 }
 
-func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (any, error) {
-		return []byte(tokenSecret), nil
-	})
-	if err != nil || !token.Valid {
-		return uuid.Nil, err
+// TokenOption adds optional claims to a token made by MakeJWT.
+type TokenOption func(claims *Claims)
+
+func WithRole(role string) TokenOption {
+	return func(claims *Claims) {
+		claims.Role = role
+	}
+}
+
+// ForAudience sets the "aud" claim to the services the token is meant for.
+func ForAudience(audience ...string) TokenOption {
+	return func(claims *Claims) {
+		claims.Audience = audience
 	}
+}
 
-	claims := token.Claims
-	userId_s, err := claims.GetSubject()
+// MakeJWT issues an access token signed with an HMAC secret. Servers that
+// rotate keys use a Keyring instead.
+func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration, options ...TokenOption) (string, error) {
+	return secretKeyring(tokenSecret).MakeJWT(userID, expiresIn, options...)
+}
+
+// ParseJWT validates a token made by MakeJWT and returns its claims.
+func ParseJWT(tokenString, tokenSecret string, options ...ValidationOption) (*Claims, error) {
+	return secretKeyring(tokenSecret).ParseJWT(tokenString, options...)
+}
+
+func secretKeyring(tokenSecret string) *Keyring {
+	keyring, _ := NewKeyring(NewHMACKey("", []byte(tokenSecret)))
+	return keyring
+}
+
+func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
+	claims, err := ParseJWT(tokenString, tokenSecret)
 	if err != nil {
 		return uuid.Nil, err
 	}
-	userId, err := uuid.Parse(userId_s)
+
+	userId, err := claims.UserID()
 	if err != nil {
 		return uuid.Nil, err
 	}
@@ -75,6 +110,14 @@ func MakeRefreshToken() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
+// HashToken returns the form refresh tokens and personal access tokens are
+// stored in. Both are random, so a plain SHA-256 is enough to keep a database
+// leak from handing out live tokens.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 func GetBearerToken(headers http.Header) (string, error) {
 	return GetKeyFromHeader(headers, "Bearer")
 }
@@ -83,10 +126,12 @@ func GetAPIKey(headers http.Header) (string, error) {
 	return GetKeyFromHeader(headers, "ApiKey")
 }
 
+var ErrMissingAuthorization = errors.New("authorization header is missing")
+
 func GetKeyFromHeader(headers http.Header, key string) (string, error) {
 	authHeader := headers.Get("Authorization")
 	if authHeader == "" {
-		return "", errors.New("authorization header is missing")
+		return "", ErrMissingAuthorization
 	}
 	if !strings.HasPrefix(authHeader, key+" ") {
 		return "", errors.New("authorization header does not start with '" + key + " '")
@@ -1,10 +1,15 @@
 package auth
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
 	"net/http"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
@@ -163,3 +168,381 @@ func TestGetBearerToken(t *testing.T) {
 		})
 	}
 }
+
+func TestParseJWTRole(t *testing.T) {
+	userId := uuid.New()
+	tokenSecret := "superSecretKey123!"
+
+	tests := []struct {
+		name         string
+		options      []TokenOption
+		expectedRole string
+	}{
+		{
+			name:         "Token without role",
+			expectedRole: "",
+		},
+		{
+			name:         "Token with role",
+			options:      []TokenOption{WithRole(RoleModerator)},
+			expectedRole: RoleModerator,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := MakeJWT(userId, tokenSecret, time.Hour, tt.options...)
+			if err != nil {
+				t.Fatalf("MakeJWT() error = %v", err)
+			}
+			claims, err := ParseJWT(token, tokenSecret)
+			if err != nil {
+				t.Fatalf("ParseJWT() error = %v", err)
+			}
+			if claims.Role != tt.expectedRole {
+				t.Errorf("ParseJWT() role = %q, want %q", claims.Role, tt.expectedRole)
+			}
+		})
+	}
+}
+
+func TestHasRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		required string
+		expected bool
+	}{
+		{name: "Same role", role: RoleModerator, required: RoleModerator, expected: true},
+		{name: "Higher role", role: RoleAdmin, required: RoleModerator, expected: true},
+		{name: "Lower role", role: RoleUser, required: RoleModerator, expected: false},
+		{name: "Missing role", role: "", required: RoleUser, expected: false},
+		{name: "Unknown role", role: "superuser", required: RoleUser, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasRole(tt.role, tt.required); got != tt.expected {
+				t.Errorf("HasRole(%q, %q) = %v, want %v", tt.role, tt.required, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLockoutPolicyDelay(t *testing.T) {
+	policy := LockoutPolicy{Threshold: 3, BaseDelay: 30 * time.Second, MaxDelay: 5 * time.Minute}
+
+	tests := []struct {
+		name     string
+		failures int
+		expected time.Duration
+	}{
+		{name: "No failures", failures: 0, expected: 0},
+		{name: "Below threshold", failures: 2, expected: 0},
+		{name: "At threshold", failures: 3, expected: 30 * time.Second},
+		{name: "Doubles", failures: 5, expected: 2 * time.Minute},
+		{name: "Capped", failures: 7, expected: 5 * time.Minute},
+		{name: "Capped for many failures", failures: 1000, expected: 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Delay(tt.failures); got != tt.expected {
+				t.Errorf("Delay(%d) = %v, want %v", tt.failures, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		expected string
+	}{
+		{name: "Known digest", token: "abc", expected: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{name: "Empty token", token: "", expected: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HashToken(tt.token); got != tt.expected {
+				t.Errorf("HashToken(%q) = %q, want %q", tt.token, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMakeJWTTokenID(t *testing.T) {
+	userId := uuid.New()
+	tokenSecret := "superSecretKey123!"
+
+	ids := map[string]bool{}
+	for range 2 {
+		token, err := MakeJWT(userId, tokenSecret, time.Hour)
+		if err != nil {
+			t.Fatalf("MakeJWT() error = %v", err)
+		}
+		claims, err := ParseJWT(token, tokenSecret)
+		if err != nil {
+			t.Fatalf("ParseJWT() error = %v", err)
+		}
+		if claims.ID == "" {
+			t.Fatalf("ParseJWT() jti is empty")
+		}
+		ids[claims.ID] = true
+	}
+	if len(ids) != 2 {
+		t.Errorf("MakeJWT() issued the same jti twice")
+	}
+}
+
+func TestClaimsIssuedBefore(t *testing.T) {
+	issuedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		issuedAt *jwt.NumericDate
+		t        time.Time
+		expected bool
+	}{
+		{name: "Issued earlier", issuedAt: jwt.NewNumericDate(issuedAt), t: issuedAt.Add(time.Minute), expected: true},
+		{name: "Issued later", issuedAt: jwt.NewNumericDate(issuedAt), t: issuedAt.Add(-time.Minute), expected: false},
+		{name: "Issued in the same second", issuedAt: jwt.NewNumericDate(issuedAt), t: issuedAt.Add(500 * time.Millisecond), expected: false},
+		{name: "Missing issue time", issuedAt: nil, t: issuedAt, expected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &Claims{RegisteredClaims: jwt.RegisteredClaims{IssuedAt: tt.issuedAt}}
+			if got := claims.IssuedBefore(tt.t); got != tt.expected {
+				t.Errorf("IssuedBefore() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	userId := uuid.New()
+
+	_, oldPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	rsaPrivateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	oldKey := NewEd25519Key(oldPrivateKey)
+	newKey, err := NewRSAKey(rsaPrivateKey)
+	if err != nil {
+		t.Fatalf("NewRSAKey() error = %v", err)
+	}
+	legacyKey := NewHMACKey("", []byte("superSecretKey123!"))
+
+	before, _ := NewKeyring(oldKey, legacyKey)
+	after, _ := NewKeyring(newKey, oldKey, legacyKey)
+	unrelated, _ := NewKeyring(NewHMACKey("other", []byte("anotherSecret")))
+
+	legacyToken, _ := MakeJWT(userId, "superSecretKey123!", time.Hour)
+	oldToken, _ := before.MakeJWT(userId, time.Hour)
+	newToken, _ := after.MakeJWT(userId, time.Hour)
+	unrelatedToken, _ := unrelated.MakeJWT(userId, time.Hour)
+
+	tests := []struct {
+		name    string
+		keyring *Keyring
+		token   string
+		wantErr bool
+	}{
+		{name: "Token signed by the signing key", keyring: after, token: newToken, wantErr: false},
+		{name: "Token signed by a retired key", keyring: after, token: oldToken, wantErr: false},
+		{name: "Token without key ID", keyring: after, token: legacyToken, wantErr: false},
+		{name: "Token signed by a newer key", keyring: before, token: newToken, wantErr: true},
+		{name: "Token signed by an unknown key", keyring: after, token: unrelatedToken, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.keyring.ParseJWT(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseJWT() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && claims.Subject != userId.String() {
+				t.Errorf("ParseJWT() subject = %v, want %v", claims.Subject, userId)
+			}
+		})
+	}
+}
+
+func TestKeyringRejectsAlgorithmConfusion(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	key := NewEd25519Key(privateKey)
+	keyring, _ := NewKeyring(key)
+
+	// An HS256 token using the published public key as its secret.
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{})
+	token.Header["kid"] = key.ID
+	signed, err := token.SignedString([]byte(privateKey.Public().(ed25519.PublicKey)))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	_, err = keyring.ParseJWT(signed)
+	if !errors.Is(err, ErrUnexpectedAlgorithm) {
+		t.Errorf("ParseJWT() error = %v, want %v", err, ErrUnexpectedAlgorithm)
+	}
+}
+
+func TestKeyringJWKS(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	key := NewEd25519Key(privateKey)
+	keyring, _ := NewKeyring(key, NewHMACKey("", []byte("superSecretKey123!")))
+
+	jwks := keyring.JWKS()
+	if len(jwks.Keys) != 1 {
+		t.Fatalf("JWKS() returned %d keys, want 1", len(jwks.Keys))
+	}
+	jwk := jwks.Keys[0]
+	if jwk.Kid != key.ID || jwk.Kty != "OKP" || jwk.Alg != "EdDSA" {
+		t.Errorf("JWKS() key = %+v", jwk)
+	}
+}
+
+func TestParseJWTValidation(t *testing.T) {
+	userId := uuid.New()
+	tokenSecret := "superSecretKey123!"
+
+	makeToken := func(expiresIn time.Duration, options ...TokenOption) string {
+		token, err := MakeJWT(userId, tokenSecret, expiresIn, options...)
+		if err != nil {
+			t.Fatalf("MakeJWT() error = %v", err)
+		}
+		return token
+	}
+	otherIssuer := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{RegisteredClaims: jwt.RegisteredClaims{
+		Issuer:    "someone-else",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		Subject:   userId.String(),
+	}})
+	otherIssuerToken, _ := otherIssuer.SignedString([]byte(tokenSecret))
+
+	tests := []struct {
+		name        string
+		token       string
+		secret      string
+		options     []ValidationOption
+		expectedErr error
+	}{
+		{
+			name:   "Valid token",
+			token:  makeToken(time.Hour, ForAudience("api")),
+			secret: tokenSecret,
+			options: []ValidationOption{
+				RequireIssuer(Issuer),
+				RequireAudience("api"),
+				AllowAlgorithms("HS256"),
+			},
+		},
+		{
+			name:        "Expired token",
+			token:       makeToken(-time.Minute),
+			secret:      tokenSecret,
+			expectedErr: ErrTokenExpired,
+		},
+		{
+			name:    "Expired token within clock skew",
+			token:   makeToken(-time.Minute),
+			secret:  tokenSecret,
+			options: []ValidationOption{AllowClockSkew(2 * time.Minute)},
+		},
+		{
+			name:        "Wrong secret",
+			token:       makeToken(time.Hour),
+			secret:      "wrongSecret",
+			expectedErr: ErrTokenSignatureInvalid,
+		},
+		{
+			name:        "Wrong audience",
+			token:       makeToken(time.Hour, ForAudience("other")),
+			secret:      tokenSecret,
+			options:     []ValidationOption{RequireAudience("api")},
+			expectedErr: ErrTokenWrongAudience,
+		},
+		{
+			name:        "Missing audience",
+			token:       makeToken(time.Hour),
+			secret:      tokenSecret,
+			options:     []ValidationOption{RequireAudience("api")},
+			expectedErr: ErrTokenWrongAudience,
+		},
+		{
+			name:        "Wrong issuer",
+			token:       otherIssuerToken,
+			secret:      tokenSecret,
+			options:     []ValidationOption{RequireIssuer(Issuer)},
+			expectedErr: ErrTokenWrongIssuer,
+		},
+		{
+			name:        "Algorithm not allowed",
+			token:       makeToken(time.Hour),
+			secret:      tokenSecret,
+			options:     []ValidationOption{AllowAlgorithms("EdDSA")},
+			expectedErr: ErrUnexpectedAlgorithm,
+		},
+		{
+			name:        "Malformed token",
+			token:       "not.a.token",
+			secret:      tokenSecret,
+			expectedErr: ErrTokenMalformed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJWT(tt.token, tt.secret, tt.options...)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("ParseJWT() error = %v, want %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestClaimsHasScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   []string
+		scope    string
+		expected bool
+	}{
+		{name: "Login token", scopes: nil, scope: ScopeChirpsWrite, expected: true},
+		{name: "Granted scope", scopes: []string{ScopeChirpsRead, ScopeChirpsWrite}, scope: ScopeChirpsWrite, expected: true},
+		{name: "Missing scope", scopes: []string{ScopeChirpsRead}, scope: ScopeChirpsWrite, expected: false},
+		{name: "No scopes", scopes: []string{}, scope: ScopeChirpsRead, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &Claims{Scopes: tt.scopes}
+			if got := claims.HasScope(tt.scope); got != tt.expected {
+				t.Errorf("HasScope(%q) = %v, want %v", tt.scope, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMakePersonalAccessToken(t *testing.T) {
+	token, err := MakePersonalAccessToken()
+	if err != nil {
+		t.Fatalf("MakePersonalAccessToken() error = %v", err)
+	}
+	if !IsPersonalAccessToken(token) {
+		t.Errorf("IsPersonalAccessToken(%q) = false, want true", token)
+	}
+
+	jwtToken, err := MakeJWT(uuid.New(), "superSecretKey123!", time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT() error = %v", err)
+	}
+	if IsPersonalAccessToken(jwtToken) {
+		t.Errorf("IsPersonalAccessToken() = true for a JWT")
+	}
+}
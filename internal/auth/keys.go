@@ -0,0 +1,264 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const minRSAKeyBits = 2048
+
+// algorithms are the JWS algorithms keys sign with.
+var algorithms = []string{
+	jwt.SigningMethodHS256.Alg(),
+	jwt.SigningMethodEdDSA.Alg(),
+	jwt.SigningMethodRS256.Alg(),
+}
+
+func IsValidAlgorithm(algorithm string) bool {
+	return slices.Contains(algorithms, algorithm)
+}
+
+// Key is a key access tokens are signed or verified with. Tokens name the key
+// that signed them in their "kid" header.
+type Key struct {
+	ID        string
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+}
+
+// NewHMACKey returns an HS256 key. HMAC keys are secret, so they are never
+// published in the JWKS.
+func NewHMACKey(id string, secret []byte) *Key {
+	return &Key{ID: id, method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}
+}
+
+// NewEd25519Key returns an EdDSA key whose ID is its JWK thumbprint.
+func NewEd25519Key(privateKey ed25519.PrivateKey) *Key {
+	key := &Key{
+		method:    jwt.SigningMethodEdDSA,
+		signKey:   privateKey,
+		verifyKey: privateKey.Public(),
+	}
+	key.ID = key.thumbprint()
+	return key
+}
+
+// NewRSAKey returns an RS256 key whose ID is its JWK thumbprint.
+func NewRSAKey(privateKey *rsa.PrivateKey) (*Key, error) {
+	if privateKey.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSAKeyBits)
+	}
+	key := &Key{
+		method:    jwt.SigningMethodRS256,
+		signKey:   privateKey,
+		verifyKey: &privateKey.PublicKey,
+	}
+	key.ID = key.thumbprint()
+	return key, nil
+}
+
+// ParsePrivateKeyPEM reads an Ed25519 or RSA private key in PKCS #8 form, or
+// an RSA key in PKCS #1 form.
+func ParsePrivateKeyPEM(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var privateKey any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch privateKey := privateKey.(type) {
+	case ed25519.PrivateKey:
+		return NewEd25519Key(privateKey), nil
+	case *rsa.PrivateKey:
+		return NewRSAKey(privateKey)
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", privateKey)
+	}
+}
+
+// Algorithm returns the JWS algorithm of the key.
+func (key *Key) Algorithm() string {
+	return key.method.Alg()
+}
+
+// JWK is the public part of a key in JSON Web Key form.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public key in JWK form, or false for HMAC keys, which have
+// no public part.
+func (key *Key) JWK() (JWK, bool) {
+	jwk := JWK{Use: "sig", Alg: key.Algorithm(), Kid: key.ID}
+	switch publicKey := key.verifyKey.(type) {
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	default:
+		return JWK{}, false
+	}
+	return jwk, true
+}
+
+// thumbprint returns the RFC 7638 thumbprint of the public key: the hash of
+// its required members in lexicographic order.
+func (key *Key) thumbprint() string {
+	jwk, _ := key.JWK()
+	var members any
+	switch jwk.Kty {
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	}
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Keyring signs access tokens with one key and verifies them with any of its
+// keys. Rotating keys means adding a new signing key and keeping the old one
+// for verification until the tokens it signed have expired.
+type Keyring struct {
+	signing *Key
+	keys    map[string]*Key
+	ordered []*Key
+}
+
+// NewKeyring returns a keyring that signs with signing and also verifies
+// tokens signed by verifyOnly.
+func NewKeyring(signing *Key, verifyOnly ...*Key) (*Keyring, error) {
+	keyring := &Keyring{signing: signing, keys: map[string]*Key{}}
+	for _, key := range append([]*Key{signing}, verifyOnly...) {
+		if _, ok := keyring.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key ID %q", key.ID)
+		}
+		keyring.keys[key.ID] = key
+		keyring.ordered = append(keyring.ordered, key)
+	}
+	return keyring, nil
+}
+
+// MakeJWT issues an access token to userID signed with the signing key.
+func (keyring *Keyring) MakeJWT(userID uuid.UUID, expiresIn time.Duration, options ...TokenOption) (string, error) {
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    Issuer,
+			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(expiresIn)),
+			Subject:   userID.String(),
+			ID:        uuid.NewString(),
+		},
+	}
+	for _, option := range options {
+		option(claims)
+	}
+	token := jwt.NewWithClaims(keyring.signing.method, claims)
+	if keyring.signing.ID != "" {
+		token.Header["kid"] = keyring.signing.ID
+	}
+
+	signed, err := token.SignedString(keyring.signing.signKey)
+	if err != nil {
+		return "", err
+	}
+	return signed, nil
+}
+
+// ParseJWT validates a token signed by one of the keyring's keys and returns
+// its claims. Tokens without a "kid" header are checked against the key with
+// an empty ID, which is how tokens from before key rotation are accepted.
+// Validation errors are the Err* errors of this package.
+func (keyring *Keyring) ParseJWT(tokenString string, options ...ValidationOption) (*Claims, error) {
+	v := &validation{}
+	for _, option := range options {
+		option(v)
+	}
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keyring.keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		if token.Method.Alg() != key.Algorithm() || !v.allowsAlgorithm(key.Algorithm()) {
+			return nil, ErrUnexpectedAlgorithm
+		}
+		return key.verifyKey, nil
+	}, v.parserOptions()...)
+	if err != nil {
+		return nil, validationError(err)
+	}
+	if !token.Valid {
+		return nil, errors.New("token is invalid")
+	}
+	err = v.checkClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// JWKS returns the public keys of the keyring for services that verify
+// tokens themselves.
+func (keyring *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range keyring.ordered {
+		if jwk, ok := key.JWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
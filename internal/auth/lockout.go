@@ -0,0 +1,28 @@
+package auth
+
+import "time"
+
+// LockoutPolicy decides how long logins are refused after failed attempts.
+type LockoutPolicy struct {
+	// Threshold is the number of failures allowed before logins are refused.
+	Threshold int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// Delay returns how long after the last of the given number of consecutive
+// failures logins are refused. It is zero below the threshold and doubles with
+// every failure from there, up to MaxDelay.
+func (policy LockoutPolicy) Delay(failures int) time.Duration {
+	if failures < policy.Threshold {
+		return 0
+	}
+	delay := policy.BaseDelay
+	for range failures - policy.Threshold {
+		delay *= 2
+		if delay >= policy.MaxDelay {
+			return policy.MaxDelay
+		}
+	}
+	return min(delay, policy.MaxDelay)
+}
@@ -0,0 +1,24 @@
+package auth
+
+import "slices"
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// roles lists the roles from least to most privileged. Each role includes the
+// permissions of the ones before it.
+var roles = []string{RoleUser, RoleModerator, RoleAdmin}
+
+func IsValidRole(role string) bool {
+	return slices.Contains(roles, role)
+}
+
+// HasRole reports whether role grants at least the permissions of required.
+// Unknown roles grant nothing.
+func HasRole(role, required string) bool {
+	index := slices.Index(roles, role)
+	return index >= 0 && index >= slices.Index(roles, required)
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	ScopeChirpsRead  = "chirps:read"
+	ScopeChirpsWrite = "chirps:write"
+	ScopeUsersRead   = "users:read"
+	ScopeUsersWrite  = "users:write"
+)
+
+var scopes = []string{ScopeChirpsRead, ScopeChirpsWrite, ScopeUsersRead, ScopeUsersWrite}
+
+// personalAccessTokenPrefix tells personal access tokens apart from JWTs
+// on the bearer path, and makes leaked tokens easy to scan for.
+const personalAccessTokenPrefix = "chirpy_pat_"
+
+func IsValidScope(scope string) bool {
+	return slices.Contains(scopes, scope)
+}
+
+// HasScope reports whether the token may be used for scope. Only personal
+// access tokens are limited to scopes; access tokens from a login may do
+// anything their user can.
+func (claims *Claims) HasScope(scope string) bool {
+	return claims.Scopes == nil || slices.Contains(claims.Scopes, scope)
+}
+
+// NewPersonalAccessTokenClaims returns the claims a personal access token
+// stands for. It grants no role, so it cannot be used on admin routes.
+func NewPersonalAccessTokenClaims(userID, tokenID uuid.UUID, scopes []string) *Claims {
+	return &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: userID.String(),
+			ID:      tokenID.String(),
+		},
+		// Never nil, so that a token without scopes may do nothing.
+		Scopes: append([]string{}, scopes...),
+	}
+}
+
+func MakePersonalAccessToken() (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+	return personalAccessTokenPrefix + hex.EncodeToString(bytes), nil
+}
+
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, personalAccessTokenPrefix)
+}
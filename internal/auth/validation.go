@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Issuer is the "iss" claim of the access tokens Chirpy issues.
+const Issuer = "chirpy"
+
+// Errors returned when an access token fails validation, so callers can tell
+// clients why a token was rejected.
+var (
+	ErrTokenMalformed        = errors.New("token is malformed")
+	ErrTokenExpired          = errors.New("token has expired")
+	ErrTokenNotValidYet      = errors.New("token is not valid yet")
+	ErrTokenSignatureInvalid = errors.New("token signature is invalid")
+	ErrTokenWrongIssuer      = errors.New("token has the wrong issuer")
+	ErrTokenWrongAudience    = errors.New("token has the wrong audience")
+	ErrUnknownKey            = errors.New("token is signed with an unknown key")
+	ErrUnexpectedAlgorithm   = errors.New("token is signed with an unexpected algorithm")
+)
+
+type validation struct {
+	algorithms []string
+	issuer     string
+	audience   string
+	clockSkew  time.Duration
+}
+
+// ValidationOption tightens the checks ParseJWT makes. Without options a
+// token must be signed by a known key with that key's algorithm and must not
+// have expired.
+type ValidationOption func(v *validation)
+
+// AllowAlgorithms rejects tokens signed with any other algorithm, even by a
+// known key.
+func AllowAlgorithms(algorithms ...string) ValidationOption {
+	return func(v *validation) {
+		v.algorithms = algorithms
+	}
+}
+
+func RequireIssuer(issuer string) ValidationOption {
+	return func(v *validation) {
+		v.issuer = issuer
+	}
+}
+
+func RequireAudience(audience string) ValidationOption {
+	return func(v *validation) {
+		v.audience = audience
+	}
+}
+
+// AllowClockSkew accepts tokens that expired or became valid up to skew ago,
+// to tolerate clocks that differ between the issuer and the verifier.
+func AllowClockSkew(skew time.Duration) ValidationOption {
+	return func(v *validation) {
+		v.clockSkew = skew
+	}
+}
+
+func (v *validation) parserOptions() []jwt.ParserOption {
+	return []jwt.ParserOption{jwt.WithExpirationRequired(), jwt.WithLeeway(v.clockSkew)}
+}
+
+// checkClaims checks the issuer and audience. The jwt package reports a
+// missing claim differently from a wrong one, but to clients both mean the
+// token was not meant for this service.
+func (v *validation) checkClaims(claims *Claims) error {
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return ErrTokenWrongIssuer
+	}
+	if v.audience != "" && !slices.Contains(claims.Audience, v.audience) {
+		return ErrTokenWrongAudience
+	}
+	return nil
+}
+
+func (v *validation) allowsAlgorithm(algorithm string) bool {
+	return len(v.algorithms) == 0 || slices.Contains(v.algorithms, algorithm)
+}
+
+// validationError translates the errors of the jwt package into the errors
+// of this package.
+func validationError(err error) error {
+	for _, e := range []error{ErrUnknownKey, ErrUnexpectedAlgorithm} {
+		if errors.Is(err, e) {
+			return e
+		}
+	}
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return ErrTokenNotValidYet
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return ErrTokenSignatureInvalid
+	case errors.Is(err, jwt.ErrTokenMalformed), errors.Is(err, jwt.ErrTokenRequiredClaimMissing):
+		return ErrTokenMalformed
+	}
+	return err
+}
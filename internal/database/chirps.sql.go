@@ -7,23 +7,36 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
-VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2)
-RETURNING id, created_at, updated_at, body, user_id
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to, kind, repost_of, rechirp_of)
+VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2, $3, $4, $5, $6)
+RETURNING id, created_at, updated_at, body, user_id, edited_at, in_reply_to, kind, repost_of, search_vector, hidden_at, rechirp_of
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.UUID
+	Body      string
+	UserID    uuid.UUID
+	InReplyTo uuid.NullUUID
+	Kind      string
+	RepostOf  uuid.NullUUID
+	RechirpOf uuid.NullUUID
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.Body,
+		arg.UserID,
+		arg.InReplyTo,
+		arg.Kind,
+		arg.RepostOf,
+		arg.RechirpOf,
+	)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -31,6 +44,13 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.EditedAt,
+		&i.InReplyTo,
+		&i.Kind,
+		&i.RepostOf,
+		&i.SearchVector,
+		&i.HiddenAt,
+		&i.RechirpOf,
 	)
 	return i, err
 }
@@ -46,7 +66,7 @@ func (q *Queries) DeleteChirpById(ctx context.Context, id uuid.UUID) error {
 }
 
 const getChirpById = `-- name: GetChirpById :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps
+SELECT id, created_at, updated_at, body, user_id, edited_at, in_reply_to, kind, repost_of, search_vector, hidden_at, rechirp_of FROM chirps
 WHERE id = $1
 `
 
@@ -59,17 +79,421 @@ func (q *Queries) GetChirpById(ctx context.Context, id uuid.UUID) (Chirp, error)
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.EditedAt,
+		&i.InReplyTo,
+		&i.Kind,
+		&i.RepostOf,
+		&i.SearchVector,
+		&i.HiddenAt,
+		&i.RechirpOf,
+	)
+	return i, err
+}
+
+const getChirpForUpdate = `-- name: GetChirpForUpdate :one
+SELECT id, created_at, updated_at, body, user_id, edited_at, in_reply_to, kind, repost_of, search_vector, hidden_at, rechirp_of FROM chirps
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetChirpForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpForUpdate, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.EditedAt,
+		&i.InReplyTo,
+		&i.Kind,
+		&i.RepostOf,
+		&i.SearchVector,
+		&i.HiddenAt,
+		&i.RechirpOf,
 	)
 	return i, err
 }
 
-const getChirps = `-- name: GetChirps :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-ORDER BY created_at
+const hideChirp = `-- name: HideChirp :exec
+UPDATE chirps
+SET hidden_at = NOW()
+WHERE id = $1 AND hidden_at IS NULL
+`
+
+func (q *Queries) HideChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, hideChirp, id)
+	return err
+}
+
+const listChirpAncestors = `-- name: ListChirpAncestors :many
+WITH RECURSIVE ancestors (id, depth) AS (
+    SELECT parent.id, 1
+    FROM chirps child
+        JOIN chirps parent ON parent.id = child.in_reply_to
+    WHERE child.id = $1
+    UNION ALL
+    SELECT parent.id, ancestors.depth + 1
+    FROM ancestors
+        JOIN chirps child ON child.id = ancestors.id
+        JOIN chirps parent ON parent.id = child.in_reply_to
+    WHERE ancestors.depth < $2::int
+)
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.edited_at, chirps.in_reply_to, chirps.kind, chirps.repost_of, chirps.search_vector, chirps.hidden_at, chirps.rechirp_of, ancestors.depth
+FROM ancestors
+    JOIN chirps ON chirps.id = ancestors.id
+ORDER BY ancestors.depth DESC
+`
+
+type ListChirpAncestorsParams struct {
+	ChirpID  uuid.UUID
+	MaxDepth int32
+}
+
+type ListChirpAncestorsRow struct {
+	Chirp Chirp
+	Depth int32
+}
+
+func (q *Queries) ListChirpAncestors(ctx context.Context, arg ListChirpAncestorsParams) ([]ListChirpAncestorsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpAncestors, arg.ChirpID, arg.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListChirpAncestorsRow
+	for rows.Next() {
+		var i ListChirpAncestorsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.EditedAt,
+			&i.Chirp.InReplyTo,
+			&i.Chirp.Kind,
+			&i.Chirp.RepostOf,
+			&i.Chirp.SearchVector,
+			&i.Chirp.HiddenAt,
+			&i.Chirp.RechirpOf,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpReplies = `-- name: ListChirpReplies :many
+WITH RECURSIVE replies (id, depth) AS (
+    SELECT chirps.id, 1
+    FROM chirps
+    WHERE chirps.in_reply_to = $1
+    UNION ALL
+    SELECT chirps.id, replies.depth + 1
+    FROM replies
+        JOIN chirps ON chirps.in_reply_to = replies.id
+    WHERE replies.depth < $2::int
+)
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.edited_at, chirps.in_reply_to, chirps.kind, chirps.repost_of, chirps.search_vector, chirps.hidden_at, chirps.rechirp_of, replies.depth
+FROM replies
+    JOIN chirps ON chirps.id = replies.id
+ORDER BY replies.depth, chirps.created_at, chirps.id
+LIMIT $3
+`
+
+type ListChirpRepliesParams struct {
+	ChirpID    uuid.UUID
+	MaxDepth   int32
+	MaxReplies int32
+}
+
+type ListChirpRepliesRow struct {
+	Chirp Chirp
+	Depth int32
+}
+
+func (q *Queries) ListChirpReplies(ctx context.Context, arg ListChirpRepliesParams) ([]ListChirpRepliesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpReplies, arg.ChirpID, arg.MaxDepth, arg.MaxReplies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListChirpRepliesRow
+	for rows.Next() {
+		var i ListChirpRepliesRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.EditedAt,
+			&i.Chirp.InReplyTo,
+			&i.Chirp.Kind,
+			&i.Chirp.RepostOf,
+			&i.Chirp.SearchVector,
+			&i.Chirp.HiddenAt,
+			&i.Chirp.RechirpOf,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsByCreatedAt = `-- name: ListChirpsByCreatedAt :many
+SELECT id, created_at, updated_at, body, user_id, edited_at, in_reply_to, kind, repost_of, search_vector, hidden_at, rechirp_of FROM chirps
+WHERE (COALESCE(cardinality($1::uuid[]), 0) = 0 OR user_id = ANY($1::uuid[]))
+  AND ($2::timestamp IS NULL OR created_at >= $2)
+  AND ($3::timestamp IS NULL OR created_at < $3)
+  AND ($4::timestamp IS NULL OR (created_at, id) > ($4, $5::uuid))
+  AND (hidden_at IS NULL OR $6::boolean OR user_id = $7::uuid)
+  AND user_id NOT IN (
+    SELECT blocked_id FROM blocks WHERE blocker_id = $7::uuid
+    UNION ALL
+    SELECT blocker_id FROM blocks WHERE blocked_id = $7::uuid
+  )
+  AND (
+    COALESCE(cardinality($1::uuid[]), 0) > 0
+    OR user_id NOT IN (SELECT muted_id FROM mutes WHERE muter_id = $7::uuid)
+  )
+ORDER BY created_at ASC, id ASC
+LIMIT $8
+`
+
+type ListChirpsByCreatedAtParams struct {
+	AuthorIds     []uuid.UUID
+	Since         sql.NullTime
+	Until         sql.NullTime
+	CursorTime    sql.NullTime
+	CursorID      uuid.NullUUID
+	IncludeHidden bool
+	ViewerID      uuid.NullUUID
+	PageSize      int32
+}
+
+func (q *Queries) ListChirpsByCreatedAt(ctx context.Context, arg ListChirpsByCreatedAtParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByCreatedAt,
+		pq.Array(arg.AuthorIds),
+		arg.Since,
+		arg.Until,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.IncludeHidden,
+		arg.ViewerID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.EditedAt,
+			&i.InReplyTo,
+			&i.Kind,
+			&i.RepostOf,
+			&i.SearchVector,
+			&i.HiddenAt,
+			&i.RechirpOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsByCreatedAtDesc = `-- name: ListChirpsByCreatedAtDesc :many
+SELECT id, created_at, updated_at, body, user_id, edited_at, in_reply_to, kind, repost_of, search_vector, hidden_at, rechirp_of FROM chirps
+WHERE (COALESCE(cardinality($1::uuid[]), 0) = 0 OR user_id = ANY($1::uuid[]))
+  AND ($2::timestamp IS NULL OR created_at >= $2)
+  AND ($3::timestamp IS NULL OR created_at < $3)
+  AND ($4::timestamp IS NULL OR (created_at, id) < ($4, $5::uuid))
+  AND (hidden_at IS NULL OR $6::boolean OR user_id = $7::uuid)
+  AND user_id NOT IN (
+    SELECT blocked_id FROM blocks WHERE blocker_id = $7::uuid
+    UNION ALL
+    SELECT blocker_id FROM blocks WHERE blocked_id = $7::uuid
+  )
+  AND (
+    COALESCE(cardinality($1::uuid[]), 0) > 0
+    OR user_id NOT IN (SELECT muted_id FROM mutes WHERE muter_id = $7::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $8
+`
+
+type ListChirpsByCreatedAtDescParams struct {
+	AuthorIds     []uuid.UUID
+	Since         sql.NullTime
+	Until         sql.NullTime
+	CursorTime    sql.NullTime
+	CursorID      uuid.NullUUID
+	IncludeHidden bool
+	ViewerID      uuid.NullUUID
+	PageSize      int32
+}
+
+func (q *Queries) ListChirpsByCreatedAtDesc(ctx context.Context, arg ListChirpsByCreatedAtDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByCreatedAtDesc,
+		pq.Array(arg.AuthorIds),
+		arg.Since,
+		arg.Until,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.IncludeHidden,
+		arg.ViewerID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.EditedAt,
+			&i.InReplyTo,
+			&i.Kind,
+			&i.RepostOf,
+			&i.SearchVector,
+			&i.HiddenAt,
+			&i.RechirpOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsByIds = `-- name: ListChirpsByIds :many
+SELECT id, created_at, updated_at, body, user_id, edited_at, in_reply_to, kind, repost_of, search_vector, hidden_at, rechirp_of FROM chirps
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) ListChirpsByIds(ctx context.Context, ids []uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByIds, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.EditedAt,
+			&i.InReplyTo,
+			&i.Kind,
+			&i.RepostOf,
+			&i.SearchVector,
+			&i.HiddenAt,
+			&i.RechirpOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsByUpdatedAt = `-- name: ListChirpsByUpdatedAt :many
+SELECT id, created_at, updated_at, body, user_id, edited_at, in_reply_to, kind, repost_of, search_vector, hidden_at, rechirp_of FROM chirps
+WHERE (COALESCE(cardinality($1::uuid[]), 0) = 0 OR user_id = ANY($1::uuid[]))
+  AND ($2::timestamp IS NULL OR created_at >= $2)
+  AND ($3::timestamp IS NULL OR created_at < $3)
+  AND ($4::timestamp IS NULL OR (updated_at, id) > ($4, $5::uuid))
+  AND (hidden_at IS NULL OR $6::boolean OR user_id = $7::uuid)
+  AND user_id NOT IN (
+    SELECT blocked_id FROM blocks WHERE blocker_id = $7::uuid
+    UNION ALL
+    SELECT blocker_id FROM blocks WHERE blocked_id = $7::uuid
+  )
+  AND (
+    COALESCE(cardinality($1::uuid[]), 0) > 0
+    OR user_id NOT IN (SELECT muted_id FROM mutes WHERE muter_id = $7::uuid)
+  )
+ORDER BY updated_at ASC, id ASC
+LIMIT $8
 `
 
-func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirps)
+type ListChirpsByUpdatedAtParams struct {
+	AuthorIds     []uuid.UUID
+	Since         sql.NullTime
+	Until         sql.NullTime
+	CursorTime    sql.NullTime
+	CursorID      uuid.NullUUID
+	IncludeHidden bool
+	ViewerID      uuid.NullUUID
+	PageSize      int32
+}
+
+func (q *Queries) ListChirpsByUpdatedAt(ctx context.Context, arg ListChirpsByUpdatedAtParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByUpdatedAt,
+		pq.Array(arg.AuthorIds),
+		arg.Since,
+		arg.Until,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.IncludeHidden,
+		arg.ViewerID,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -83,6 +507,13 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.EditedAt,
+			&i.InReplyTo,
+			&i.Kind,
+			&i.RepostOf,
+			&i.SearchVector,
+			&i.HiddenAt,
+			&i.RechirpOf,
 		); err != nil {
 			return nil, err
 		}
@@ -97,14 +528,48 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 	return items, nil
 }
 
-const getChirpsByAuthorId = `-- name: GetChirpsByAuthorId :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-WHERE user_id = $1
-ORDER BY created_at
+const listChirpsByUpdatedAtDesc = `-- name: ListChirpsByUpdatedAtDesc :many
+SELECT id, created_at, updated_at, body, user_id, edited_at, in_reply_to, kind, repost_of, search_vector, hidden_at, rechirp_of FROM chirps
+WHERE (COALESCE(cardinality($1::uuid[]), 0) = 0 OR user_id = ANY($1::uuid[]))
+  AND ($2::timestamp IS NULL OR created_at >= $2)
+  AND ($3::timestamp IS NULL OR created_at < $3)
+  AND ($4::timestamp IS NULL OR (updated_at, id) < ($4, $5::uuid))
+  AND (hidden_at IS NULL OR $6::boolean OR user_id = $7::uuid)
+  AND user_id NOT IN (
+    SELECT blocked_id FROM blocks WHERE blocker_id = $7::uuid
+    UNION ALL
+    SELECT blocker_id FROM blocks WHERE blocked_id = $7::uuid
+  )
+  AND (
+    COALESCE(cardinality($1::uuid[]), 0) > 0
+    OR user_id NOT IN (SELECT muted_id FROM mutes WHERE muter_id = $7::uuid)
+  )
+ORDER BY updated_at DESC, id DESC
+LIMIT $8
 `
 
-func (q *Queries) GetChirpsByAuthorId(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsByAuthorId, userID)
+type ListChirpsByUpdatedAtDescParams struct {
+	AuthorIds     []uuid.UUID
+	Since         sql.NullTime
+	Until         sql.NullTime
+	CursorTime    sql.NullTime
+	CursorID      uuid.NullUUID
+	IncludeHidden bool
+	ViewerID      uuid.NullUUID
+	PageSize      int32
+}
+
+func (q *Queries) ListChirpsByUpdatedAtDesc(ctx context.Context, arg ListChirpsByUpdatedAtDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByUpdatedAtDesc,
+		pq.Array(arg.AuthorIds),
+		arg.Since,
+		arg.Until,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.IncludeHidden,
+		arg.ViewerID,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -118,6 +583,13 @@ func (q *Queries) GetChirpsByAuthorId(ctx context.Context, userID uuid.UUID) ([]
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.EditedAt,
+			&i.InReplyTo,
+			&i.Kind,
+			&i.RepostOf,
+			&i.SearchVector,
+			&i.HiddenAt,
+			&i.RechirpOf,
 		); err != nil {
 			return nil, err
 		}
@@ -131,3 +603,201 @@ func (q *Queries) GetChirpsByAuthorId(ctx context.Context, userID uuid.UUID) ([]
 	}
 	return items, nil
 }
+
+const listTimelineChirps = `-- name: ListTimelineChirps :many
+SELECT id, created_at, updated_at, body, user_id, edited_at, in_reply_to, kind, repost_of, search_vector, hidden_at, rechirp_of FROM chirps
+WHERE (
+    user_id = $1
+    OR user_id IN (SELECT followee_id FROM follows WHERE follower_id = $1)
+  )
+  AND (hidden_at IS NULL OR user_id = $1)
+  AND user_id NOT IN (
+    SELECT blocked_id FROM blocks WHERE blocker_id = $1
+    UNION ALL
+    SELECT blocker_id FROM blocks WHERE blocked_id = $1
+  )
+  AND user_id NOT IN (SELECT muted_id FROM mutes WHERE muter_id = $1)
+  AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) < ($2, $3::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListTimelineChirpsParams struct {
+	UserID     uuid.UUID
+	CursorTime sql.NullTime
+	CursorID   uuid.NullUUID
+	PageSize   int32
+}
+
+func (q *Queries) ListTimelineChirps(ctx context.Context, arg ListTimelineChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listTimelineChirps,
+		arg.UserID,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.EditedAt,
+			&i.InReplyTo,
+			&i.Kind,
+			&i.RepostOf,
+			&i.SearchVector,
+			&i.HiddenAt,
+			&i.RechirpOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.edited_at, chirps.in_reply_to, chirps.kind, chirps.repost_of, chirps.search_vector, chirps.hidden_at, chirps.rechirp_of, results.rank, ts_headline('english', chirps.body, results.query, $1::text) AS headline
+FROM (
+    SELECT chirps.id, ts_rank(chirps.search_vector, query) AS rank, query
+    FROM chirps, websearch_to_tsquery('english', $2::text) query
+    WHERE chirps.search_vector @@ query AND chirps.hidden_at IS NULL
+) results
+    JOIN chirps ON chirps.id = results.id
+WHERE (
+    $3::real IS NULL
+    OR (results.rank, chirps.id) < ($3::real, $4::uuid)
+  )
+  AND chirps.user_id NOT IN (
+    SELECT blocked_id FROM blocks WHERE blocker_id = $5::uuid
+    UNION ALL
+    SELECT blocker_id FROM blocks WHERE blocked_id = $5::uuid
+    UNION ALL
+    SELECT muted_id FROM mutes WHERE muter_id = $5::uuid
+  )
+ORDER BY results.rank DESC, chirps.id DESC
+LIMIT $6
+`
+
+type SearchChirpsParams struct {
+	HeadlineOptions string
+	Query           string
+	CursorRank      sql.NullFloat64
+	CursorID        uuid.NullUUID
+	ViewerID        uuid.NullUUID
+	PageSize        int32
+}
+
+type SearchChirpsRow struct {
+	Chirp    Chirp
+	Rank     float32
+	Headline string
+}
+
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]SearchChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps,
+		arg.HeadlineOptions,
+		arg.Query,
+		arg.CursorRank,
+		arg.CursorID,
+		arg.ViewerID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsRow
+	for rows.Next() {
+		var i SearchChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.EditedAt,
+			&i.Chirp.InReplyTo,
+			&i.Chirp.Kind,
+			&i.Chirp.RepostOf,
+			&i.Chirp.SearchVector,
+			&i.Chirp.HiddenAt,
+			&i.Chirp.RechirpOf,
+			&i.Rank,
+			&i.Headline,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unhideChirp = `-- name: UnhideChirp :execrows
+UPDATE chirps
+SET hidden_at = NULL
+WHERE id = $1
+`
+
+func (q *Queries) UnhideChirp(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unhideChirp, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $1, updated_at = NOW(), edited_at = NOW()
+WHERE id = $2
+RETURNING id, created_at, updated_at, body, user_id, edited_at, in_reply_to, kind, repost_of, search_vector, hidden_at, rechirp_of
+`
+
+type UpdateChirpBodyParams struct {
+	Body string
+	ID   uuid.UUID
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.Body, arg.ID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.EditedAt,
+		&i.InReplyTo,
+		&i.Kind,
+		&i.RepostOf,
+		&i.SearchVector,
+		&i.HiddenAt,
+		&i.RechirpOf,
+	)
+	return i, err
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// openTestDB migrates a fresh schema in the database at CHIRPY_TEST_DB_URL and
+// returns a connection that uses it. Tests that need it are skipped when the
+// variable is not set.
+func openTestDB(t *testing.T) *sql.Conn {
+	t.Helper()
+	dbUrl := os.Getenv("CHIRPY_TEST_DB_URL")
+	if dbUrl == "" {
+		t.Skip("CHIRPY_TEST_DB_URL is not set")
+	}
+
+	ctx := context.Background()
+	db, err := sql.Open("postgres", dbUrl)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Conn() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	schema := "chirpy_test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	_, err = conn.ExecContext(ctx, "CREATE SCHEMA "+schema+"; SET search_path TO "+schema+", public")
+	if err != nil {
+		t.Fatalf("creating schema: %v", err)
+	}
+	t.Cleanup(func() { conn.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE") })
+
+	migrations, err := filepath.Glob("../../sql/migrations/*.sql")
+	if err != nil {
+		t.Fatalf("filepath.Glob() error = %v", err)
+	}
+	for _, migration := range migrations {
+		data, err := os.ReadFile(migration)
+		if err != nil {
+			t.Fatalf("reading %s: %v", migration, err)
+		}
+		up, _, _ := strings.Cut(string(data), "-- +goose Down")
+		_, err = conn.ExecContext(ctx, up)
+		if err != nil {
+			t.Fatalf("applying %s: %v", migration, err)
+		}
+	}
+	return conn
+}
+
+func TestDeleteUserDeletesRechirps(t *testing.T) {
+	conn := openTestDB(t)
+	q := New(conn)
+	ctx := context.Background()
+
+	createUser := func(t *testing.T) User {
+		t.Helper()
+		user, err := q.CreateUser(ctx, CreateUserParams{
+			Email:          uuid.NewString() + "@example.com",
+			HashedPassword: "unused",
+		})
+		if err != nil {
+			t.Fatalf("CreateUser() error = %v", err)
+		}
+		return user
+	}
+	deleteUser := func(t *testing.T, userId uuid.UUID) {
+		t.Helper()
+		_, err := conn.ExecContext(ctx, "DELETE FROM users WHERE id = $1", userId)
+		if err != nil {
+			t.Fatalf("deleting user: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		ownRepost     bool
+		kind          string
+		deleteAll     bool
+		expectDeleted bool
+	}{
+		{name: "Own rechirp", ownRepost: true, kind: "rechirp", expectDeleted: true},
+		{name: "Own rechirp on reset", ownRepost: true, kind: "rechirp", deleteAll: true, expectDeleted: true},
+		{name: "Rechirp by another user", kind: "rechirp", expectDeleted: true},
+		{name: "Quote by another user survives", kind: "quote"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			author := createUser(t)
+			reposter := createUser(t)
+			if tt.ownRepost {
+				reposter = author
+			}
+
+			original, err := q.CreateChirp(ctx, CreateChirpParams{Body: "Hello", UserID: author.ID, Kind: "chirp"})
+			if err != nil {
+				t.Fatalf("CreateChirp() error = %v", err)
+			}
+			repostParams := CreateChirpParams{UserID: reposter.ID, Kind: tt.kind}
+			if tt.kind == "rechirp" {
+				repostParams.RechirpOf = uuid.NullUUID{UUID: original.ID, Valid: true}
+			} else {
+				repostParams.Body = "Look at this"
+				repostParams.RepostOf = uuid.NullUUID{UUID: original.ID, Valid: true}
+			}
+			repost, err := q.CreateChirp(ctx, repostParams)
+			if err != nil {
+				t.Fatalf("CreateChirp() error = %v", err)
+			}
+
+			if tt.deleteAll {
+				err = q.DeleteAllUsers(ctx)
+				if err != nil {
+					t.Fatalf("DeleteAllUsers() error = %v", err)
+				}
+			} else {
+				deleteUser(t, author.ID)
+			}
+
+			got, err := q.GetChirpById(ctx, repost.ID)
+			if tt.expectDeleted {
+				if !errors.Is(err, sql.ErrNoRows) {
+					t.Errorf("GetChirpById() error = %v, want %v", err, sql.ErrNoRows)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetChirpById() error = %v", err)
+			}
+			if got.RepostOf.Valid {
+				t.Errorf("GetChirpById() repost_of = %v, want NULL", got.RepostOf.UUID)
+			}
+		})
+	}
+}
@@ -11,28 +11,145 @@ import (
 	"github.com/google/uuid"
 )
 
+type Block struct {
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
+	CreatedAt time.Time
+}
+
 type Chirp struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Body         string
+	UserID       uuid.UUID
+	EditedAt     sql.NullTime
+	InReplyTo    uuid.NullUUID
+	Kind         string
+	RepostOf     uuid.NullUUID
+	SearchVector interface{}
+	HiddenAt     sql.NullTime
+	RechirpOf    uuid.NullUUID
+}
+
+type ChirpFlag struct {
+	ChirpID   uuid.UUID
+	Word      string
+	CreatedAt time.Time
+}
+
+type ChirpHashtag struct {
+	ChirpID   uuid.UUID
+	Tag       string
+	CreatedAt time.Time
+}
+
+type ChirpLike struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpMention struct {
+	ChirpID     uuid.UUID
+	UserID      uuid.UUID
+	StartOffset int32
+	EndOffset   int32
+	CreatedAt   time.Time
+}
+
+type ChirpRevision struct {
+	ID         uuid.UUID
+	ChirpID    uuid.UUID
+	Body       string
+	CreatedAt  time.Time
+	ReplacedAt time.Time
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type LoginAttempt struct {
 	ID        uuid.UUID
+	UserID    uuid.NullUUID
+	Email     string
+	Ip        string
+	Success   bool
+	CreatedAt time.Time
+}
+
+type ModerationRule struct {
+	Word      string
+	Action    string
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Body      string
-	UserID    uuid.UUID
 }
 
-type RefreshToken struct {
-	Token     string
+type Mute struct {
+	MuterID   uuid.UUID
+	MutedID   uuid.UUID
 	CreatedAt time.Time
+}
+
+type PersonalAccessToken struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Name       string
+	TokenHash  string
+	Scopes     []string
+	CreatedAt  time.Time
+	LastUsedAt sql.NullTime
+	ExpiresAt  sql.NullTime
+	RevokedAt  sql.NullTime
+}
+
+type RateLimitBucket struct {
+	Key       string
+	Tokens    float64
+	Allowed   bool
 	UpdatedAt time.Time
-	UserID    uuid.UUID
-	ExpiresAt time.Time
-	RevokedAt sql.NullTime
+}
+
+type RefreshToken struct {
+	TokenHash  string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	ExpiresAt  time.Time
+	RevokedAt  sql.NullTime
+	FamilyID   uuid.UUID
+	ReplacedBy sql.NullString
+	LastUsedAt time.Time
+	UserAgent  string
+	Ip         string
+}
+
+type Report struct {
+	ID         uuid.UUID
+	ReporterID uuid.UUID
+	ChirpID    uuid.NullUUID
+	UserID     uuid.UUID
+	Reason     string
+	Status     string
+	CreatedAt  time.Time
+	ResolvedAt sql.NullTime
+	ResolvedBy uuid.NullUUID
 }
 
 type User struct {
-	ID             uuid.UUID
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	Email          string
-	HashedPassword string
-	IsChirpyRed    bool
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Email            string
+	HashedPassword   string
+	IsChirpyRed      bool
+	Username         sql.NullString
+	DisplayName      string
+	Bio              string
+	SuspendedAt      sql.NullTime
+	Role             string
+	TokensValidAfter sql.NullTime
 }
@@ -7,23 +7,41 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const bootstrapAdmin = `-- name: BootstrapAdmin :execrows
+UPDATE users
+SET role = 'admin', updated_at = NOW()
+WHERE email = $1
+  AND NOT EXISTS (SELECT 1 FROM users WHERE role = 'admin')
+`
+
+func (q *Queries) BootstrapAdmin(ctx context.Context, email string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, bootstrapAdmin, email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password, is_chirpy_red)
-VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2, false)
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, is_chirpy_red, username)
+VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2, false, $3)
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, suspended_at, role, tokens_valid_after
 `
 
 type CreateUserParams struct {
 	Email          string
 	HashedPassword string
+	Username       sql.NullString
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword)
+	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword, arg.Username)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -32,6 +50,12 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.TokensValidAfter,
 	)
 	return i, err
 }
@@ -45,8 +69,19 @@ func (q *Queries) DeleteAllUsers(ctx context.Context) error {
 	return err
 }
 
+const getTokensValidAfter = `-- name: GetTokensValidAfter :one
+SELECT tokens_valid_after FROM users WHERE id = $1
+`
+
+func (q *Queries) GetTokensValidAfter(ctx context.Context, id uuid.UUID) (sql.NullTime, error) {
+	row := q.db.QueryRowContext(ctx, getTokensValidAfter, id)
+	var tokens_valid_after sql.NullTime
+	err := row.Scan(&tokens_valid_after)
+	return tokens_valid_after, err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users WHERE email = $1
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, suspended_at, role, tokens_valid_after FROM users WHERE email = $1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -59,6 +94,189 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.TokensValidAfter,
+	)
+	return i, err
+}
+
+const getUserById = `-- name: GetUserById :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, suspended_at, role, tokens_valid_after FROM users WHERE id = $1
+`
+
+func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserById, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.TokensValidAfter,
+	)
+	return i, err
+}
+
+const getUserByUsername = `-- name: GetUserByUsername :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, suspended_at, role, tokens_valid_after FROM users WHERE lower(username) = lower($1)
+`
+
+func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByUsername, username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.TokensValidAfter,
+	)
+	return i, err
+}
+
+const listUsersByIds = `-- name: ListUsersByIds :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, suspended_at, role, tokens_valid_after FROM users WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) ListUsersByIds(ctx context.Context, ids []uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersByIds, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.Username,
+			&i.DisplayName,
+			&i.Bio,
+			&i.SuspendedAt,
+			&i.Role,
+			&i.TokensValidAfter,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersByUsernames = `-- name: ListUsersByUsernames :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, suspended_at, role, tokens_valid_after FROM users WHERE lower(username) = ANY($1::text[])
+`
+
+func (q *Queries) ListUsersByUsernames(ctx context.Context, usernames []string) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersByUsernames, pq.Array(usernames))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.Username,
+			&i.DisplayName,
+			&i.Bio,
+			&i.SuspendedAt,
+			&i.Role,
+			&i.TokensValidAfter,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeAccessTokens = `-- name: RevokeAccessTokens :exec
+UPDATE users
+SET tokens_valid_after = NOW(), updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) RevokeAccessTokens(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, revokeAccessTokens, id)
+	return err
+}
+
+const suspendUser = `-- name: SuspendUser :exec
+UPDATE users
+SET suspended_at = NOW(), tokens_valid_after = NOW(), updated_at = NOW()
+WHERE id = $1 AND suspended_at IS NULL
+`
+
+func (q *Queries) SuspendUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, suspendUser, id)
+	return err
+}
+
+const unsuspendUser = `-- name: UnsuspendUser :one
+UPDATE users
+SET suspended_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, suspended_at, role, tokens_valid_after
+`
+
+func (q *Queries) UnsuspendUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, unsuspendUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.TokensValidAfter,
 	)
 	return i, err
 }
@@ -81,19 +299,174 @@ func (q *Queries) UpdateChirpyRedStatus(ctx context.Context, arg UpdateChirpyRed
 
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
-SET email = $1, hashed_password = $2, updated_at = NOW()
-WHERE id = $3
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+SET email = $1,
+    hashed_password = $2,
+    username = COALESCE($3::text, username),
+    display_name = COALESCE($4::text, display_name),
+    bio = COALESCE($5::text, bio),
+    updated_at = NOW()
+WHERE id = $6
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, suspended_at, role, tokens_valid_after
 `
 
 type UpdateUserParams struct {
 	Email          string
 	HashedPassword string
+	Username       sql.NullString
+	DisplayName    sql.NullString
+	Bio            sql.NullString
 	ID             uuid.UUID
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUser, arg.Email, arg.HashedPassword, arg.ID)
+	row := q.db.QueryRowContext(ctx, updateUser,
+		arg.Email,
+		arg.HashedPassword,
+		arg.Username,
+		arg.DisplayName,
+		arg.Bio,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.TokensValidAfter,
+	)
+	return i, err
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :one
+UPDATE users
+SET email = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, suspended_at, role, tokens_valid_after
+`
+
+type UpdateUserEmailParams struct {
+	Email string
+	ID    uuid.UUID
+}
+
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserEmail, arg.Email, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.TokensValidAfter,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users
+SET hashed_password = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, suspended_at, role, tokens_valid_after
+`
+
+type UpdateUserPasswordParams struct {
+	HashedPassword string
+	ID             uuid.UUID
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPassword, arg.HashedPassword, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.TokensValidAfter,
+	)
+	return i, err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+SET username = COALESCE($1::text, username),
+    display_name = COALESCE($2::text, display_name),
+    bio = COALESCE($3::text, bio),
+    updated_at = NOW()
+WHERE id = $4
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, suspended_at, role, tokens_valid_after
+`
+
+type UpdateUserProfileParams struct {
+	Username    sql.NullString
+	DisplayName sql.NullString
+	Bio         sql.NullString
+	ID          uuid.UUID
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserProfile,
+		arg.Username,
+		arg.DisplayName,
+		arg.Bio,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.TokensValidAfter,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $1,
+    updated_at = NOW(),
+    tokens_valid_after = CASE WHEN role <> $1 THEN NOW() ELSE tokens_valid_after END
+WHERE id = $2
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, suspended_at, role, tokens_valid_after
+`
+
+type UpdateUserRoleParams struct {
+	Role string
+	ID   uuid.UUID
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserRole, arg.Role, arg.ID)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -102,6 +475,12 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.TokensValidAfter,
 	)
 	return i, err
 }
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRevokeAccessTokensTimeZone(t *testing.T) {
+	conn := openTestDB(t)
+	q := New(conn)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		timeZone string
+	}{
+		{name: "UTC", timeZone: "UTC"},
+		{name: "Ahead of UTC", timeZone: "Asia/Tokyo"},
+		{name: "Behind UTC", timeZone: "America/Los_Angeles"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := conn.ExecContext(ctx, "SET TIME ZONE '"+tt.timeZone+"'")
+			if err != nil {
+				t.Fatalf("setting time zone: %v", err)
+			}
+			user, err := q.CreateUser(ctx, CreateUserParams{
+				Email:          uuid.NewString() + "@example.com",
+				HashedPassword: "unused",
+			})
+			if err != nil {
+				t.Fatalf("CreateUser() error = %v", err)
+			}
+
+			before := time.Now().Add(-time.Second)
+			err = q.RevokeAccessTokens(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("RevokeAccessTokens() error = %v", err)
+			}
+			after := time.Now().Add(time.Second)
+
+			got, err := q.GetTokensValidAfter(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("GetTokensValidAfter() error = %v", err)
+			}
+			if !got.Valid || got.Time.Before(before) || got.Time.After(after) {
+				t.Errorf("GetTokensValidAfter() = %v, want between %v and %v", got, before, after)
+			}
+		})
+	}
+}
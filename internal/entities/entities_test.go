@@ -0,0 +1,101 @@
+package entities
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHashtags(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{
+			name:     "Single hashtag",
+			text:     "Hello #golang",
+			expected: []string{"golang"},
+		},
+		{
+			name:     "Case is folded and duplicates removed",
+			text:     "#Go #GO #go",
+			expected: []string{"go"},
+		},
+		{
+			name:     "Punctuation ends a hashtag",
+			text:     "Loving #chirpy! And #boot_dev, too.",
+			expected: []string{"chirpy", "boot_dev"},
+		},
+		{
+			name:     "Unicode hashtags",
+			text:     "#Café #ＧＯ #Straße",
+			expected: []string{"café", "go", "strasse"},
+		},
+		{
+			name:     "Numbers only are not hashtags",
+			text:     "We're #1 and #2023",
+			expected: []string{},
+		},
+		{
+			name:     "Hash inside a word is not a hashtag",
+			text:     "issue#42 and C#",
+			expected: []string{},
+		},
+		{
+			name:     "Length limit counts characters",
+			text:     "#" + strings.Repeat("é", 100) + " #" + strings.Repeat("é", 101),
+			expected: []string{strings.Repeat("é", 100)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Hashtags(tt.text)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("Hashtags() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []Mention
+	}{
+		{
+			name:     "Single mention",
+			text:     "Hi @alice!",
+			expected: []Mention{{Username: "alice", Start: 3, End: 9}},
+		},
+		{
+			name: "Offsets count code points",
+			text: "Héllo 👋 @bob_42 and @carol",
+			expected: []Mention{
+				{Username: "bob_42", Start: 8, End: 15},
+				{Username: "carol", Start: 20, End: 26},
+			},
+		},
+		{
+			name:     "Email addresses are not mentions",
+			text:     "Write to alice@example.com",
+			expected: []Mention{},
+		},
+		{
+			name:     "Too short or too long usernames are ignored",
+			text:     "@ab @abcdefghijklmnopqrstuvwxyz12345",
+			expected: []Mention{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Mentions(tt.text)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("Mentions() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+const maxHashtagLength = 100
+
+// A hashtag starts with '#' at the beginning of the text or after a character
+// that cannot be part of a word, so "a#b" and "&#39;" are not hashtags.
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{M}\p{N}_&/])[#＃]([\p{L}\p{M}\p{N}_]+)`)
+
+var folder = cases.Fold()
+
+// Hashtags returns the normalised hashtags in text, without the leading '#',
+// in order of first appearance and without duplicates.
+func Hashtags(text string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, match := range hashtagPattern.FindAllStringSubmatch(text, -1) {
+		tag := NormalizeHashtag(match[1])
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// NormalizeHashtag case-folds a hashtag and brings it into Unicode NFKC form
+// so that "#Go", "#GO" and "#ｇｏ" are the same tag. It returns an empty string
+// for tags that are only digits or too long.
+func NormalizeHashtag(tag string) string {
+	tag = strings.TrimLeft(tag, "#＃")
+	tag = norm.NFKC.String(folder.String(norm.NFKC.String(tag)))
+	if tag == "" || utf8.RuneCountInString(tag) > maxHashtagLength {
+		return ""
+	}
+	if strings.IndexFunc(tag, func(r rune) bool { return !unicode.IsDigit(r) }) == -1 {
+		return ""
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsMark(r) && !unicode.IsNumber(r) && r != '_' {
+			return ""
+		}
+	}
+	return tag
+}
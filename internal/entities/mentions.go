@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Usernames are 3 to 30 ASCII letters, digits or underscores. A mention must
+// not be glued to a preceding word, which keeps email addresses out.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{M}\p{N}_@.])(@[A-Za-z0-9_]{3,30})\b`)
+
+// Mention is an @username token in a text. Start and End are offsets in
+// Unicode code points, End being exclusive and the '@' included.
+type Mention struct {
+	Username string
+	Start    int
+	End      int
+}
+
+// Mentions returns the @username tokens in text in order of appearance.
+func Mentions(text string) []Mention {
+	mentions := []Mention{}
+	for _, match := range mentionPattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[2], match[3]
+		if end < len(text) && text[end] == '@' {
+			continue
+		}
+		runeStart := utf8.RuneCountInString(text[:start])
+		mentions = append(mentions, Mention{
+			Username: strings.TrimPrefix(text[start:end], "@"),
+			Start:    runeStart,
+			End:      runeStart + utf8.RuneCountInString(text[start:end]),
+		})
+	}
+	return mentions
+}
@@ -0,0 +1,109 @@
+// Package moderation checks user-written text against a list of prohibited
+// words. Each rule decides whether a match is masked, rejected or flagged for
+// review by an admin.
+package moderation
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type Action string
+
+const (
+	ActionMask   Action = "mask"
+	ActionReject Action = "reject"
+	ActionFlag   Action = "flag"
+)
+
+const mask = "****"
+
+func ParseAction(s string) (Action, error) {
+	switch action := Action(strings.ToLower(strings.TrimSpace(s))); action {
+	case ActionMask, ActionReject, ActionFlag:
+		return action, nil
+	default:
+		return "", fmt.Errorf("unknown moderation action %q", s)
+	}
+}
+
+type Rule struct {
+	Word   string
+	Action Action
+}
+
+type Match struct {
+	Word   string
+	Action Action
+}
+
+// Result is the outcome of moderating a text. Body is the text with masked
+// words replaced; it should not be stored if Rejected is set.
+type Result struct {
+	Body     string
+	Rejected bool
+	Flagged  bool
+	Matches  []Match
+}
+
+// Filter moderates text. Implementations must be safe for concurrent use.
+type Filter interface {
+	Apply(text string) Result
+}
+
+// RuleSetter is implemented by filters whose rules can be replaced while the
+// server is running.
+type RuleSetter interface {
+	SetRules(rules []Rule)
+}
+
+// ParseRules reads rules with one "word" or "word,action" per line. Words
+// without an action are masked. Blank lines and lines starting with '#' are
+// ignored.
+func ParseRules(r io.Reader) ([]Rule, error) {
+	rules := []Rule{}
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		word, actionName, found := strings.Cut(line, ",")
+		rule := Rule{Word: strings.TrimSpace(word), Action: ActionMask}
+		if found {
+			action, err := ParseAction(actionName)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			rule.Action = action
+		}
+		if normalizeToken(rule.Word) == "" {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, errors.New("rule has no word"))
+		}
+		rules = append(rules, rule)
+	}
+	return rules, scanner.Err()
+}
+
+func LoadRulesFile(path string) ([]Rule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseRules(file)
+}
+
+// IsWord reports whether s is a single word as the filters see it, which is
+// what a rule needs to be able to match.
+func IsWord(s string) bool {
+	tokens := tokenize(s)
+	return len(tokens) == 1 && tokens[0].word
+}
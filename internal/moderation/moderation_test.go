@@ -0,0 +1,112 @@
+package moderation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWordFilterApply(t *testing.T) {
+	filter := NewWordFilter([]Rule{
+		{Word: "kerfuffle", Action: ActionMask},
+		{Word: "sharbert", Action: ActionMask},
+		{Word: "fornax", Action: ActionReject},
+		{Word: "grumble", Action: ActionFlag},
+	})
+
+	tests := []struct {
+		name         string
+		text         string
+		expectedBody string
+		rejected     bool
+		flagged      bool
+	}{
+		{
+			name:         "Clean text is unchanged",
+			text:         "I had something interesting for breakfast",
+			expectedBody: "I had something interesting for breakfast",
+		},
+		{
+			name:         "Masks words regardless of case",
+			text:         "This is a Kerfuffle opinion I need to share",
+			expectedBody: "This is a **** opinion I need to share",
+		},
+		{
+			name:         "Keeps punctuation around masked words",
+			text:         "What a kerfuffle! (Sharbert.)",
+			expectedBody: "What a ****! (****.)",
+		},
+		{
+			name:         "Masks obfuscated words",
+			text:         "k3rfuffl3 and Shärbert and ker​fuffle",
+			expectedBody: "**** and **** and ****",
+		},
+		{
+			name:         "Masks words starting with a substitute",
+			text:         "$harbert! But not $5 or @alice",
+			expectedBody: "****! But not $5 or @alice",
+		},
+		{
+			name:         "Does not mask words containing a rule word",
+			text:         "kerfuffles",
+			expectedBody: "kerfuffles",
+		},
+		{
+			name:         "Rejects",
+			text:         "Look at f0rnax",
+			expectedBody: "Look at f0rnax",
+			rejected:     true,
+		},
+		{
+			name:         "Flags",
+			text:         "grumble grumble",
+			expectedBody: "grumble grumble",
+			flagged:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := filter.Apply(tt.text)
+			if result.Body != tt.expectedBody {
+				t.Errorf("Apply() body = %q, want %q", result.Body, tt.expectedBody)
+			}
+			if result.Rejected != tt.rejected {
+				t.Errorf("Apply() rejected = %v, want %v", result.Rejected, tt.rejected)
+			}
+			if result.Flagged != tt.flagged {
+				t.Errorf("Apply() flagged = %v, want %v", result.Flagged, tt.flagged)
+			}
+		})
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	input := `# default word list
+kerfuffle
+fornax, reject
+
+grumble,flag
+`
+	rules, err := ParseRules(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseRules() error = %v", err)
+	}
+	expected := []Rule{
+		{Word: "kerfuffle", Action: ActionMask},
+		{Word: "fornax", Action: ActionReject},
+		{Word: "grumble", Action: ActionFlag},
+	}
+	if len(rules) != len(expected) {
+		t.Fatalf("ParseRules() = %v, want %v", rules, expected)
+	}
+	for i := range expected {
+		if rules[i] != expected[i] {
+			t.Errorf("ParseRules()[%d] = %v, want %v", i, rules[i], expected[i])
+		}
+	}
+
+	_, err = ParseRules(strings.NewReader("kerfuffle,explode\n"))
+	if err == nil {
+		t.Errorf("ParseRules() with unknown action: expected error")
+	}
+}
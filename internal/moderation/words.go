@@ -0,0 +1,141 @@
+package moderation
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Characters commonly substituted for letters to dodge word filters.
+var leetReplacer = strings.NewReplacer(
+	"0", "o",
+	"1", "i",
+	"3", "e",
+	"4", "a",
+	"5", "s",
+	"7", "t",
+	"@", "a",
+	"$", "s",
+)
+
+var folder = cases.Fold()
+
+// WordFilter matches whole words against its rules. Words are compared after
+// case folding, removing accents and invisible characters, and undoing common
+// letter substitutions, so "Kërfuffle", "KERFUFFLE" and "k3rfuffl3" all match
+// a rule for "kerfuffle".
+type WordFilter struct {
+	mu    sync.RWMutex
+	rules map[string]Rule
+}
+
+var _ Filter = (*WordFilter)(nil)
+var _ RuleSetter = (*WordFilter)(nil)
+
+func NewWordFilter(rules []Rule) *WordFilter {
+	filter := &WordFilter{}
+	filter.SetRules(rules)
+	return filter
+}
+
+// SetRules replaces all rules of the filter. When several rules normalise to
+// the same word, the last one wins.
+func (f *WordFilter) SetRules(rules []Rule) {
+	byWord := make(map[string]Rule, len(rules))
+	for _, rule := range rules {
+		word := normalizeToken(rule.Word)
+		if word != "" {
+			byWord[word] = rule
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = byWord
+}
+
+func (f *WordFilter) Apply(text string) Result {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	result := Result{}
+	var body strings.Builder
+	for _, token := range tokenize(text) {
+		rule, ok := f.rules[normalizeToken(token.text)]
+		if !token.word || !ok {
+			body.WriteString(token.text)
+			continue
+		}
+
+		result.Matches = append(result.Matches, Match{Word: rule.Word, Action: rule.Action})
+		switch rule.Action {
+		case ActionMask:
+			body.WriteString(mask)
+		case ActionReject:
+			result.Rejected = true
+			body.WriteString(token.text)
+		case ActionFlag:
+			result.Flagged = true
+			body.WriteString(token.text)
+		}
+	}
+	result.Body = body.String()
+	return result
+}
+
+type token struct {
+	text string
+	word bool
+}
+
+// tokenize splits text into alternating word and non-word tokens that add up
+// to the original text. A word starts with a letter or digit, or with an '@'
+// or '$' substitute followed by one, and continues through letters, digits,
+// combining marks, invisible format characters and the substitutes, so
+// "Kerfuffle!" is the word "Kerfuffle" followed by "!" and "$harbert" is a
+// single word.
+func tokenize(text string) []token {
+	tokens := []token{}
+	start := 0
+	inWord := false
+	for i, r := range text {
+		var isWordRune bool
+		if inWord {
+			isWordRune = isWordStart(r) || unicode.IsMark(r) || unicode.Is(unicode.Cf, r) || r == '@' || r == '$'
+		} else {
+			isWordRune = isWordStart(r)
+			if r == '@' || r == '$' {
+				next, _ := utf8.DecodeRuneInString(text[i+1:])
+				isWordRune = isWordStart(next)
+			}
+		}
+		if isWordRune != inWord && i > start {
+			tokens = append(tokens, token{text: text[start:i], word: inWord})
+			start = i
+		}
+		inWord = isWordRune
+	}
+	if start < len(text) {
+		tokens = append(tokens, token{text: text[start:], word: inWord})
+	}
+	return tokens
+}
+
+func isWordStart(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func normalizeToken(s string) string {
+	decomposed := norm.NFKD.String(folder.String(s))
+	stripped := strings.Map(func(r rune) rune {
+		if unicode.IsMark(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, decomposed)
+	return leetReplacer.Replace(norm.NFC.String(stripped))
+}
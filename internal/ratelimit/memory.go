@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// pruneInterval is how many takes a MemoryStore handles between removing
+// buckets that have filled up again.
+const pruneInterval = 1000
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time
+}
+
+// MemoryStore keeps buckets in process memory, so limits are not shared
+// between instances.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	takes   int
+	now     func() time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+func (store *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := store.now()
+	store.takes++
+	if store.takes%pruneInterval == 0 {
+		store.prune(now)
+	}
+
+	b, ok := store.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), updatedAt: now}
+		store.buckets[key] = b
+	}
+
+	b.tokens = min(float64(limit.Requests), b.tokens+now.Sub(b.updatedAt).Seconds()*limit.rate())
+	b.updatedAt = now
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	b.fullAt = now.Add(seconds((float64(limit.Requests) - b.tokens) / limit.rate()))
+
+	return newResult(limit, b.tokens, allowed), nil
+}
+
+// prune removes buckets that are full, which behave the same as missing ones.
+func (store *MemoryStore) prune(now time.Time) {
+	for key, b := range store.buckets {
+		if !b.fullAt.After(now) {
+			delete(store.buckets, key)
+		}
+	}
+}
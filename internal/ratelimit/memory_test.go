@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 3, Window: 3 * time.Second}
+
+	tests := []struct {
+		name              string
+		key               string
+		advance           time.Duration
+		expectedAllowed   bool
+		expectedRemaining int
+		expectedRetry     time.Duration
+	}{
+		{name: "First request", key: "a", expectedAllowed: true, expectedRemaining: 2},
+		{name: "Second request", key: "a", expectedAllowed: true, expectedRemaining: 1},
+		{name: "Third request", key: "a", expectedAllowed: true, expectedRemaining: 0},
+		{name: "Bucket empty", key: "a", expectedAllowed: false, expectedRemaining: 0, expectedRetry: time.Second},
+		{name: "Other key has its own bucket", key: "b", expectedAllowed: true, expectedRemaining: 2},
+		{name: "Still empty after half a token", key: "a", advance: 500 * time.Millisecond, expectedAllowed: false, expectedRetry: 500 * time.Millisecond},
+		{name: "Refilled one token", key: "a", advance: 500 * time.Millisecond, expectedAllowed: true, expectedRemaining: 0},
+		{name: "Refill stops at the limit", key: "a", advance: time.Hour, expectedAllowed: true, expectedRemaining: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			result, err := store.Take(context.Background(), tt.key, limit)
+			if err != nil {
+				t.Fatalf("Take() error = %v", err)
+			}
+			if result.Allowed != tt.expectedAllowed {
+				t.Errorf("Take() allowed = %v, want %v", result.Allowed, tt.expectedAllowed)
+			}
+			if result.Remaining != tt.expectedRemaining {
+				t.Errorf("Take() remaining = %v, want %v", result.Remaining, tt.expectedRemaining)
+			}
+			if result.RetryAfter != tt.expectedRetry {
+				t.Errorf("Take() retry after = %v, want %v", result.RetryAfter, tt.expectedRetry)
+			}
+		})
+	}
+}
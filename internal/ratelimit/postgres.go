@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/joac1144/bootdev-chirpy/internal/database"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table, so that all
+// instances of the server share the same limits.
+type PostgresStore struct {
+	db bucketQueries
+
+	mu        sync.Mutex
+	takes     int
+	maxWindow time.Duration
+}
+
+var _ Store = (*PostgresStore)(nil)
+
+// bucketQueries are the queries PostgresStore runs. Bucket ages are always
+// computed by the database, so they don't depend on the server's clock.
+type bucketQueries interface {
+	TakeRateLimitToken(ctx context.Context, arg database.TakeRateLimitTokenParams) (database.TakeRateLimitTokenRow, error)
+	DeleteStaleRateLimitBuckets(ctx context.Context, maxAgeSeconds int32) error
+}
+
+func NewPostgresStore(db *database.Queries) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (store *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	if store.shouldPrune(limit) {
+		err := store.prune(ctx)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	row, err := store.db.TakeRateLimitToken(ctx, database.TakeRateLimitTokenParams{
+		Key:        key,
+		Capacity:   float64(limit.Requests),
+		RefillRate: limit.rate(),
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return newResult(limit, row.Tokens, row.Allowed), nil
+}
+
+func (store *PostgresStore) shouldPrune(limit Limit) bool {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.maxWindow = max(store.maxWindow, limit.Window)
+	store.takes++
+	return store.takes%pruneInterval == 0
+}
+
+// prune removes buckets that have not been used for longer than the longest
+// window seen, which means they are full again.
+func (store *PostgresStore) prune(ctx context.Context) error {
+	store.mu.Lock()
+	maxWindow := store.maxWindow
+	store.mu.Unlock()
+	return store.db.DeleteStaleRateLimitBuckets(ctx, int32(math.Ceil(maxWindow.Seconds())))
+}
@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/joac1144/bootdev-chirpy/internal/database"
+)
+
+type fakeBucketQueries struct {
+	row    database.TakeRateLimitTokenRow
+	takes  []database.TakeRateLimitTokenParams
+	prunes []int32
+}
+
+func (q *fakeBucketQueries) TakeRateLimitToken(ctx context.Context, arg database.TakeRateLimitTokenParams) (database.TakeRateLimitTokenRow, error) {
+	q.takes = append(q.takes, arg)
+	return q.row, nil
+}
+
+func (q *fakeBucketQueries) DeleteStaleRateLimitBuckets(ctx context.Context, maxAgeSeconds int32) error {
+	q.prunes = append(q.prunes, maxAgeSeconds)
+	return nil
+}
+
+func TestPostgresStoreTake(t *testing.T) {
+	tests := []struct {
+		name              string
+		row               database.TakeRateLimitTokenRow
+		expectedAllowed   bool
+		expectedRemaining int
+		expectedRetry     time.Duration
+	}{
+		{
+			name:              "Tokens left",
+			row:               database.TakeRateLimitTokenRow{Tokens: 1.5, Allowed: true},
+			expectedAllowed:   true,
+			expectedRemaining: 1,
+		},
+		{
+			name:              "Bucket empty",
+			row:               database.TakeRateLimitTokenRow{Tokens: 0.5, Allowed: false},
+			expectedAllowed:   false,
+			expectedRemaining: 0,
+			expectedRetry:     500 * time.Millisecond,
+		},
+	}
+	limit := Limit{Requests: 3, Window: 3 * time.Second}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries := &fakeBucketQueries{row: tt.row}
+			store := &PostgresStore{db: queries}
+			result, err := store.Take(context.Background(), "a", limit)
+			if err != nil {
+				t.Fatalf("Take() error = %v", err)
+			}
+			if result.Allowed != tt.expectedAllowed {
+				t.Errorf("Take() allowed = %v, want %v", result.Allowed, tt.expectedAllowed)
+			}
+			if result.Remaining != tt.expectedRemaining {
+				t.Errorf("Take() remaining = %v, want %v", result.Remaining, tt.expectedRemaining)
+			}
+			if result.RetryAfter != tt.expectedRetry {
+				t.Errorf("Take() retry after = %v, want %v", result.RetryAfter, tt.expectedRetry)
+			}
+			expected := database.TakeRateLimitTokenParams{Key: "a", Capacity: 3, RefillRate: 1}
+			if !slices.Equal(queries.takes, []database.TakeRateLimitTokenParams{expected}) {
+				t.Errorf("TakeRateLimitToken() called with %v, want %v", queries.takes, expected)
+			}
+		})
+	}
+}
+
+func TestPostgresStorePrune(t *testing.T) {
+	queries := &fakeBucketQueries{row: database.TakeRateLimitTokenRow{Tokens: 1, Allowed: true}}
+	store := &PostgresStore{db: queries}
+
+	for i := 1; i <= 2*pruneInterval; i++ {
+		limit := Limit{Requests: 10, Window: time.Minute}
+		if i == 1 {
+			limit.Window = 90500 * time.Millisecond
+		}
+		_, err := store.Take(context.Background(), "a", limit)
+		if err != nil {
+			t.Fatalf("Take() error = %v", err)
+		}
+	}
+
+	// Buckets are pruned every pruneInterval takes, by the longest window
+	// seen in whole seconds.
+	expected := []int32{91, 91}
+	if !slices.Equal(queries.prunes, expected) {
+		t.Errorf("DeleteStaleRateLimitBuckets() called with %v, want %v", queries.prunes, expected)
+	}
+}
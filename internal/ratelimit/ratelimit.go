@@ -0,0 +1,54 @@
+// Package ratelimit implements token bucket rate limiting. A bucket holds up
+// to Limit.Requests tokens and refills at Limit.Requests tokens per
+// Limit.Window; every request takes one token.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// rate is the number of tokens added to a bucket per second.
+func (limit Limit) rate() float64 {
+	return float64(limit.Requests) / limit.Window.Seconds()
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long a denied client has to wait for the next token.
+	RetryAfter time.Duration
+	// ResetAfter is how long it takes for the bucket to be full again.
+	ResetAfter time.Duration
+}
+
+// Store keeps the buckets. Implementations must be safe for concurrent use.
+type Store interface {
+	// Take takes a token from the bucket identified by key, if there is one.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// newResult builds the result of a take that left tokens in the bucket.
+func newResult(limit Limit, tokens float64, allowed bool) Result {
+	result := Result{
+		Allowed:    allowed,
+		Limit:      limit.Requests,
+		Remaining:  max(0, int(math.Floor(tokens))),
+		ResetAfter: seconds((float64(limit.Requests) - tokens) / limit.rate()),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / limit.rate())
+	}
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
@@ -1,16 +1,24 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 
 	"github.com/joac1144/bootdev-chirpy/api"
+	"github.com/joac1144/bootdev-chirpy/internal/auth"
 	"github.com/joac1144/bootdev-chirpy/internal/database"
+	"github.com/joac1144/bootdev-chirpy/internal/moderation"
+	"github.com/joac1144/bootdev-chirpy/internal/ratelimit"
 )
 
 func main() {
@@ -22,6 +30,12 @@ func main() {
 	platform := os.Getenv("PLATFORM")
 	secret := os.Getenv("SECRET")
 	polkaKey := os.Getenv("POLKA_KEY")
+	moderationRulesFile := os.Getenv("MODERATION_RULES_FILE")
+	adminEmail := os.Getenv("ADMIN_EMAIL")
+	rateLimitStore := os.Getenv("RATE_LIMIT_STORE")
+	trustProxy := os.Getenv("TRUST_PROXY") == "true"
+	jwtKeyFiles := os.Getenv("JWT_KEY_FILES")
+	jwtAlgorithms := os.Getenv("JWT_ALGORITHMS")
 
 	db, err := sql.Open("postgres", dbUrl)
 	if err != nil {
@@ -30,23 +44,144 @@ func main() {
 
 	dbQueries := database.New(db)
 
-	config := &api.ApiConfig{Db: dbQueries, Platform: platform, Secret: secret, PolkaApiKey: polkaKey}
+	keys, err := loadKeyring(secret, jwtKeyFiles)
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %s", err)
+	}
+
+	config := &api.ApiConfig{
+		DbConn:              db,
+		Db:                  dbQueries,
+		Platform:            platform,
+		Keys:                keys,
+		ClockSkew:           30 * time.Second,
+		PolkaApiKey:         polkaKey,
+		Moderation:          moderation.NewWordFilter(nil),
+		ModerationRulesFile: moderationRulesFile,
+		AdminEmail:          adminEmail,
+		TrustProxy:          trustProxy,
+		LoginLockout: auth.LockoutPolicy{
+			Threshold: envInt("LOGIN_LOCKOUT_THRESHOLD", 5),
+			BaseDelay: 30 * time.Second,
+			MaxDelay:  15 * time.Minute,
+		},
+		LoginIPLockout: auth.LockoutPolicy{
+			Threshold: envInt("LOGIN_IP_LOCKOUT_THRESHOLD", 20),
+			BaseDelay: 30 * time.Second,
+			MaxDelay:  time.Hour,
+		},
+	}
+
+	switch rateLimitStore {
+	case "", "memory":
+		config.RateLimitStore = ratelimit.NewMemoryStore()
+	case "postgres":
+		config.RateLimitStore = ratelimit.NewPostgresStore(dbQueries)
+	case "none":
+	default:
+		log.Fatalf("Unknown RATE_LIMIT_STORE %q, must be 'memory', 'postgres' or 'none'", rateLimitStore)
+	}
+
+	// JWT_ALGORITHMS can drop HS256 once every token signed with SECRET has
+	// expired.
+	if jwtAlgorithms != "" {
+		for _, algorithm := range strings.Split(jwtAlgorithms, ",") {
+			algorithm = strings.TrimSpace(algorithm)
+			if !auth.IsValidAlgorithm(algorithm) {
+				log.Fatalf("Unknown algorithm %q in JWT_ALGORITHMS, must be 'HS256', 'EdDSA' or 'RS256'", algorithm)
+			}
+			config.TokenAlgorithms = append(config.TokenAlgorithms, algorithm)
+		}
+	}
+
+	err = config.ReloadModerationRules(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to load moderation rules: %s", err)
+	}
+	go config.WatchModerationRules(context.Background())
+
+	// The user with ADMIN_EMAIL is made an admin as long as there is none, so
+	// a fresh deployment has someone who can hand out roles. Once there is an
+	// admin, ADMIN_EMAIL is ignored; emails aren't verified, so anyone could
+	// have signed up with it. Users signing up with it are checked the same
+	// way in CreateUserHandler.
+	if adminEmail != "" {
+		updated, err := dbQueries.BootstrapAdmin(context.Background(), adminEmail)
+		if err != nil {
+			log.Fatalf("Failed to make %s an admin: %s", adminEmail, err)
+		}
+		if updated == 0 {
+			log.Printf("ADMIN_EMAIL %s was not made an admin: there already is one, or no user has signed up with it yet", adminEmail)
+		}
+	}
+
+	loginLimit := ratelimit.Limit{Requests: 10, Window: time.Minute}
+	signupLimit := ratelimit.Limit{Requests: 5, Window: time.Hour}
+	postChirpLimit := ratelimit.Limit{Requests: 30, Window: time.Minute}
 
+	// Routes wrapped in RequireScope or OptionalScope also accept personal
+	// access tokens with that scope; all others need an access token from a
+	// login.
 	serveMux := http.NewServeMux()
 	serveMux.Handle("/app/", config.MiddlewareMetricsInc(http.StripPrefix("/app", http.FileServer(http.Dir(filepathRoot)))))
 	serveMux.HandleFunc(api.HealthzPath, api.HealthzHandler)
-	serveMux.HandleFunc(api.GetChirpsPath, config.GetChirpsHandler)
-	serveMux.HandleFunc(api.GetChirpPath, config.GetChirpHandler)
-	serveMux.HandleFunc(api.PostChirpsPath, config.PostChirpsHandler)
-	serveMux.HandleFunc(api.DeleteChirpPath, config.DeleteChirpHandler)
-	serveMux.HandleFunc(api.CreateUserPath, config.CreateUserHandler)
+	serveMux.HandleFunc(api.JWKSPath, config.JWKSHandler)
+	serveMux.HandleFunc(api.GetChirpsPath, config.OptionalScope(auth.ScopeChirpsRead, config.GetChirpsHandler))
+	serveMux.HandleFunc(api.SearchChirpsPath, config.OptionalScope(auth.ScopeChirpsRead, config.SearchChirpsHandler))
+	serveMux.HandleFunc(api.GetChirpPath, config.OptionalScope(auth.ScopeChirpsRead, config.GetChirpHandler))
+	serveMux.HandleFunc(api.PostChirpsPath, config.RequireScope(auth.ScopeChirpsWrite, config.RateLimited("post-chirp", postChirpLimit, config.PostChirpsHandler)))
+	serveMux.HandleFunc(api.UpdateChirpPath, config.RequireScope(auth.ScopeChirpsWrite, config.UpdateChirpHandler))
+	serveMux.HandleFunc(api.DeleteChirpPath, config.RequireScope(auth.ScopeChirpsWrite, config.DeleteChirpHandler))
+	serveMux.HandleFunc(api.GetChirpRevisionsPath, config.OptionalScope(auth.ScopeChirpsRead, config.GetChirpRevisionsHandler))
+	serveMux.HandleFunc(api.GetChirpThreadPath, config.OptionalScope(auth.ScopeChirpsRead, config.GetChirpThreadHandler))
+	serveMux.HandleFunc(api.LikeChirpPath, config.RequireScope(auth.ScopeChirpsWrite, config.LikeChirpHandler))
+	serveMux.HandleFunc(api.UnlikeChirpPath, config.RequireScope(auth.ScopeChirpsWrite, config.UnlikeChirpHandler))
+	serveMux.HandleFunc(api.ReportChirpPath, config.RequireScope(auth.ScopeChirpsWrite, config.ReportChirpHandler))
+	serveMux.HandleFunc(api.GetTimelinePath, config.RequireScope(auth.ScopeChirpsRead, config.GetTimelineHandler))
+	serveMux.HandleFunc(api.GetHashtagChirpsPath, config.OptionalScope(auth.ScopeChirpsRead, config.GetHashtagChirpsHandler))
+	serveMux.HandleFunc(api.GetTrendingPath, config.OptionalScope(auth.ScopeChirpsRead, config.GetTrendingHandler))
+	serveMux.HandleFunc(api.CreateUserPath, config.RateLimited("signup", signupLimit, config.CreateUserHandler))
 	serveMux.HandleFunc(api.UpdateUserPath, config.UpdateUserHandler)
-	serveMux.HandleFunc(api.LoginPath, config.LoginHandler)
-	serveMux.HandleFunc(api.RefreshPath, config.RefreshHandler)
+	serveMux.HandleFunc(api.PatchUserPath, config.RequireScope(auth.ScopeUsersWrite, config.PatchUserHandler))
+	serveMux.HandleFunc(api.GetUserPath, config.OptionalScope(auth.ScopeUsersRead, config.GetUserHandler))
+	serveMux.HandleFunc(api.FollowUserPath, config.RequireScope(auth.ScopeUsersWrite, config.FollowUserHandler))
+	serveMux.HandleFunc(api.UnfollowUserPath, config.RequireScope(auth.ScopeUsersWrite, config.UnfollowUserHandler))
+	serveMux.HandleFunc(api.GetFollowersPath, config.OptionalScope(auth.ScopeUsersRead, config.GetFollowersHandler))
+	serveMux.HandleFunc(api.GetFollowingPath, config.OptionalScope(auth.ScopeUsersRead, config.GetFollowingHandler))
+	serveMux.HandleFunc(api.BlockUserPath, config.RequireScope(auth.ScopeUsersWrite, config.BlockUserHandler))
+	serveMux.HandleFunc(api.UnblockUserPath, config.RequireScope(auth.ScopeUsersWrite, config.UnblockUserHandler))
+	serveMux.HandleFunc(api.MuteUserPath, config.RequireScope(auth.ScopeUsersWrite, config.MuteUserHandler))
+	serveMux.HandleFunc(api.UnmuteUserPath, config.RequireScope(auth.ScopeUsersWrite, config.UnmuteUserHandler))
+	serveMux.HandleFunc(api.ReportUserPath, config.RequireScope(auth.ScopeUsersWrite, config.ReportUserHandler))
+	serveMux.HandleFunc(api.GetMentionsPath, config.RequireScope(auth.ScopeChirpsRead, config.GetMentionsHandler))
+	serveMux.HandleFunc(api.LoginPath, config.RateLimitedByIP("login", loginLimit, config.LoginHandler))
+	serveMux.HandleFunc(api.RefreshPath, config.RateLimitedByIP("refresh", loginLimit, config.RefreshHandler))
 	serveMux.HandleFunc(api.RevokePath, config.RevokeHandler)
+	serveMux.HandleFunc(api.GetSessionsPath, config.GetSessionsHandler)
+	serveMux.HandleFunc(api.RevokeSessionPath, config.RevokeSessionHandler)
+	serveMux.HandleFunc(api.RevokeAllSessionsPath, config.RevokeAllSessionsHandler)
+	serveMux.HandleFunc(api.CreateTokenPath, config.CreateTokenHandler)
+	serveMux.HandleFunc(api.GetTokensPath, config.GetTokensHandler)
+	serveMux.HandleFunc(api.RevokeTokenPath, config.RevokeTokenHandler)
 
-	serveMux.HandleFunc(api.MetricsPath, config.CountHitsHandler)
-	serveMux.HandleFunc(api.ResetPath, config.ResetHitsHandler)
+	// Everything under /admin/ needs at least a moderator; the routes that
+	// manage the deployment itself need an admin.
+	adminMux := http.NewServeMux()
+	adminMux.HandleFunc(api.MetricsPath, config.RequireRole(auth.RoleAdmin, config.CountHitsHandler))
+	adminMux.HandleFunc(api.ResetPath, config.RequireRole(auth.RoleAdmin, config.ResetHitsHandler))
+	adminMux.HandleFunc(api.GetModerationRulesPath, config.RequireRole(auth.RoleAdmin, config.GetModerationRulesHandler))
+	adminMux.HandleFunc(api.PutModerationRulePath, config.RequireRole(auth.RoleAdmin, config.PutModerationRuleHandler))
+	adminMux.HandleFunc(api.DeleteModerationRulePath, config.RequireRole(auth.RoleAdmin, config.DeleteModerationRuleHandler))
+	adminMux.HandleFunc(api.UpdateUserRolePath, config.RequireRole(auth.RoleAdmin, config.UpdateUserRoleHandler))
+	adminMux.HandleFunc(api.GetFlaggedChirpsPath, config.GetFlaggedChirpsHandler)
+	adminMux.HandleFunc(api.DismissChirpFlagsPath, config.DismissChirpFlagsHandler)
+	adminMux.HandleFunc(api.GetReportsPath, config.GetReportsHandler)
+	adminMux.HandleFunc(api.DismissReportPath, config.DismissReportHandler)
+	adminMux.HandleFunc(api.HideReportedChirpPath, config.HideReportedChirpHandler)
+	adminMux.HandleFunc(api.SuspendReportedUserPath, config.SuspendReportedUserHandler)
+	adminMux.HandleFunc(api.UnsuspendUserPath, config.UnsuspendUserHandler)
+	adminMux.HandleFunc(api.UnhideChirpPath, config.UnhideChirpHandler)
+	serveMux.Handle("/admin/", config.RequireRole(auth.RoleModerator, adminMux.ServeHTTP))
 
 	serveMux.HandleFunc(api.WebhooksPath, config.WebhooksHandler)
 
@@ -58,3 +193,45 @@ func main() {
 	log.Printf("Serving files from %s on port: %s\n", filepathRoot, port)
 	log.Fatal(server.ListenAndServe())
 }
+
+// envInt reads a positive integer from the environment, falling back to def
+// when the variable is unset.
+func envInt(name string, def int) int {
+	s := os.Getenv(name)
+	if s == "" {
+		return def
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		log.Fatalf("%s must be a positive number, got %q", name, s)
+	}
+	return n
+}
+
+// loadKeyring builds the keyring access tokens are signed with. Without
+// JWT_KEY_FILES tokens are signed with SECRET. Otherwise the first file holds
+// the signing key and the rest hold retired keys that still verify tokens;
+// SECRET keeps verifying tokens issued before the switch.
+func loadKeyring(secret, keyFiles string) (*auth.Keyring, error) {
+	secretKey := auth.NewHMACKey("", []byte(secret))
+	if keyFiles == "" {
+		return auth.NewKeyring(secretKey)
+	}
+
+	var keys []*auth.Key
+	for _, keyFile := range strings.Split(keyFiles, ",") {
+		data, err := os.ReadFile(strings.TrimSpace(keyFile))
+		if err != nil {
+			return nil, err
+		}
+		key, err := auth.ParsePrivateKeyPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", keyFile, err)
+		}
+		keys = append(keys, key)
+	}
+	if secret != "" {
+		keys = append(keys, secretKey)
+	}
+	return auth.NewKeyring(keys[0], keys[1:]...)
+}
@@ -7,9 +7,64 @@ import (
 )
 
 type Chirp struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string    `json:"body"`
-	UserID    uuid.UUID `json:"user_id"`
+	ID                  uuid.UUID  `json:"id"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	EditedAt            *time.Time `json:"edited_at"`
+	Body                string     `json:"body"`
+	Mentions            []Mention  `json:"mentions"`
+	UserID              uuid.UUID  `json:"user_id"`
+	Author              *Profile   `json:"author,omitempty"`
+	InReplyTo           *uuid.UUID `json:"in_reply_to"`
+	Kind                string     `json:"kind"`
+	RepostOf            *uuid.UUID `json:"repost_of"`
+	Original            *Chirp     `json:"original,omitempty"`
+	OriginalUnavailable bool       `json:"original_unavailable,omitempty"`
+	LikeCount           int64      `json:"like_count"`
+	LikedByMe           bool       `json:"liked_by_me"`
+	Hidden              bool       `json:"hidden,omitempty"`
+}
+
+// Mention is a resolved @username in a chirp body. Start and End are offsets
+// in Unicode code points, End being exclusive.
+type Mention struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Start    int32     `json:"start"`
+	End      int32     `json:"end"`
+}
+
+type ChirpPage struct {
+	Chirps     []Chirp `json:"chirps"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+type ChirpRevision struct {
+	ID         uuid.UUID `json:"id"`
+	ChirpID    uuid.UUID `json:"chirp_id"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+	ReplacedAt time.Time `json:"replaced_at"`
+}
+
+type ChirpThread struct {
+	Ancestors []Chirp      `json:"ancestors"`
+	Chirp     Chirp        `json:"chirp"`
+	Replies   []ChirpReply `json:"replies"`
+}
+
+type ChirpReply struct {
+	Chirp
+	Replies []ChirpReply `json:"replies"`
+}
+
+type ChirpSearchResult struct {
+	Chirp
+	Rank     float32 `json:"rank"`
+	Headline string  `json:"headline"`
+}
+
+type ChirpSearchPage struct {
+	Results    []ChirpSearchResult `json:"results"`
+	NextCursor string              `json:"next_cursor,omitempty"`
 }
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type ModerationRule struct {
+	Word      string    `json:"word"`
+	Action    string    `json:"action"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type FlaggedChirp struct {
+	Chirp
+	FlaggedWords []string  `json:"flagged_words"`
+	FlaggedAt    time.Time `json:"flagged_at"`
+}
+
+type FlaggedChirpPage struct {
+	Chirps     []FlaggedChirp `json:"chirps"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
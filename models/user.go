@@ -12,4 +12,20 @@ type User struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 	Email       string    `json:"email"`
 	IsChirpyRed bool      `json:"is_chirpy_red"`
+	Username    *string   `json:"username"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+	Role        string    `json:"role"`
+}
+
+// Profile is the public view of a user. Email is only set when users look at
+// their own profile.
+type Profile struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Username    *string   `json:"username"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+	IsChirpyRed bool      `json:"is_chirpy_red"`
+	Email       string    `json:"email,omitempty"`
 }
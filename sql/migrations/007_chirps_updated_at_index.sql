@@ -0,0 +1,5 @@
+-- +goose Up
+CREATE INDEX chirps_updated_at_id_idx ON chirps (updated_at, id);
+
+-- +goose Down
+DROP INDEX chirps_updated_at_id_idx;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Deleting a chirp detaches its replies instead of deleting other users' chirps.
+ALTER TABLE chirps ADD COLUMN in_reply_to UUID REFERENCES chirps(id) ON DELETE SET NULL;
+CREATE INDEX chirps_in_reply_to_idx ON chirps (in_reply_to);
+
+-- +goose Down
+DROP INDEX chirps_in_reply_to_idx;
+ALTER TABLE chirps DROP COLUMN in_reply_to;
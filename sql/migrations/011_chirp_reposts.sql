@@ -0,0 +1,14 @@
+-- +goose Up
+-- A rechirp repeats another chirp as is, a quote adds the reposter's own body.
+-- Rechirps are deleted together with their original; quotes survive it with
+-- repost_of set to NULL.
+ALTER TABLE chirps ADD COLUMN kind TEXT NOT NULL DEFAULT 'chirp' CHECK (kind IN ('chirp', 'rechirp', 'quote'));
+ALTER TABLE chirps ADD COLUMN repost_of UUID REFERENCES chirps(id) ON DELETE SET NULL;
+CREATE INDEX chirps_repost_of_idx ON chirps (repost_of);
+CREATE UNIQUE INDEX chirps_user_id_rechirp_idx ON chirps (user_id, repost_of) WHERE kind = 'rechirp';
+
+-- +goose Down
+DROP INDEX chirps_user_id_rechirp_idx;
+DROP INDEX chirps_repost_of_idx;
+ALTER TABLE chirps DROP COLUMN repost_of;
+ALTER TABLE chirps DROP COLUMN kind;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN username TEXT;
+ALTER TABLE users ADD COLUMN display_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN bio TEXT NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX users_username_lower_idx ON users (lower(username));
+
+-- +goose Down
+DROP INDEX users_username_lower_idx;
+ALTER TABLE users DROP COLUMN bio;
+ALTER TABLE users DROP COLUMN display_name;
+ALTER TABLE users DROP COLUMN username;
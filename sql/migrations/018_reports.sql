@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE chirps ADD COLUMN hidden_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP;
+
+CREATE TABLE reports (
+    id UUID PRIMARY KEY,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chirp_id UUID REFERENCES chirps(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'dismissed', 'actioned')),
+    created_at TIMESTAMP NOT NULL,
+    resolved_at TIMESTAMP,
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX reports_status_created_at_idx ON reports (status, created_at, id);
+CREATE UNIQUE INDEX reports_open_chirp_idx ON reports (reporter_id, chirp_id)
+    WHERE status = 'open' AND chirp_id IS NOT NULL;
+CREATE UNIQUE INDEX reports_open_user_idx ON reports (reporter_id, user_id)
+    WHERE status = 'open' AND chirp_id IS NULL;
+
+-- +goose Down
+DROP TABLE reports;
+ALTER TABLE users DROP COLUMN suspended_at;
+ALTER TABLE chirps DROP COLUMN hidden_at;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE login_attempts (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    email TEXT NOT NULL,
+    ip TEXT NOT NULL,
+    success BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX login_attempts_email_created_at_idx ON login_attempts (email, created_at);
+CREATE INDEX login_attempts_ip_created_at_idx ON login_attempts (ip, created_at);
+
+-- +goose Down
+DROP TABLE login_attempts;
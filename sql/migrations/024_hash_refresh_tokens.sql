@@ -0,0 +1,12 @@
+-- +goose Up
+-- Tokens are replaced by their SHA-256 in place, so sessions stay valid:
+-- clients keep presenting the raw token, which hashes to the stored value.
+ALTER TABLE refresh_tokens RENAME COLUMN token TO token_hash;
+UPDATE refresh_tokens SET
+    token_hash = encode(sha256(token_hash::bytea), 'hex'),
+    replaced_by = encode(sha256(replaced_by::bytea), 'hex');
+
+-- +goose Down
+-- Hashes cannot be turned back into tokens, so every session is ended.
+DELETE FROM refresh_tokens;
+ALTER TABLE refresh_tokens RENAME COLUMN token_hash TO token;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Access tokens issued before tokens_valid_after are rejected.
+ALTER TABLE users ADD COLUMN tokens_valid_after TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN tokens_valid_after;
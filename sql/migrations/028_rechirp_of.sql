@@ -0,0 +1,19 @@
+-- +goose Up
+-- Rechirps point at their original with rechirp_of, whose foreign key deletes
+-- them together with it however the original is deleted. repost_of is left to
+-- quotes, which survive their original with repost_of set to NULL.
+ALTER TABLE chirps ADD COLUMN rechirp_of UUID REFERENCES chirps(id) ON DELETE CASCADE;
+DELETE FROM chirps WHERE kind = 'rechirp' AND repost_of IS NULL;
+UPDATE chirps SET rechirp_of = repost_of, repost_of = NULL WHERE kind = 'rechirp';
+ALTER TABLE chirps ADD CONSTRAINT chirps_rechirp_of_check CHECK ((kind = 'rechirp') = (rechirp_of IS NOT NULL));
+ALTER TABLE chirps ADD CONSTRAINT chirps_repost_of_check CHECK (kind = 'quote' OR repost_of IS NULL);
+DROP INDEX chirps_user_id_rechirp_idx;
+CREATE UNIQUE INDEX chirps_rechirp_of_user_id_idx ON chirps (rechirp_of, user_id);
+
+-- +goose Down
+DROP INDEX chirps_rechirp_of_user_id_idx;
+ALTER TABLE chirps DROP CONSTRAINT chirps_repost_of_check;
+ALTER TABLE chirps DROP CONSTRAINT chirps_rechirp_of_check;
+UPDATE chirps SET repost_of = rechirp_of WHERE kind = 'rechirp';
+CREATE UNIQUE INDEX chirps_user_id_rechirp_idx ON chirps (user_id, repost_of) WHERE kind = 'rechirp';
+ALTER TABLE chirps DROP COLUMN rechirp_of;
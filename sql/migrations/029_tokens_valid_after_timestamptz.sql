@@ -0,0 +1,7 @@
+-- +goose Up
+-- tokens_valid_after is compared with the issue time of tokens, so it has to
+-- be an instant rather than a wall clock time in the session's time zone.
+ALTER TABLE users ALTER COLUMN tokens_valid_after TYPE TIMESTAMPTZ USING tokens_valid_after AT TIME ZONE 'UTC';
+
+-- +goose Down
+ALTER TABLE users ALTER COLUMN tokens_valid_after TYPE TIMESTAMP USING tokens_valid_after AT TIME ZONE 'UTC';
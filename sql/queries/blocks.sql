@@ -0,0 +1,20 @@
+-- name: CreateBlock :exec
+INSERT INTO blocks (blocker_id, blocked_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (blocker_id, blocked_id) DO NOTHING;
+
+-- name: DeleteBlock :exec
+DELETE FROM blocks
+WHERE blocker_id = $1 AND blocked_id = $2;
+
+-- name: BlockExists :one
+SELECT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocker_id = @user_id AND blocked_id = @other_user_id)
+       OR (blocker_id = @other_user_id AND blocked_id = @user_id)
+);
+
+-- name: ListBlockedUserIds :many
+SELECT blocked_id AS user_id FROM blocks WHERE blocker_id = @user_id
+UNION
+SELECT blocker_id AS user_id FROM blocks WHERE blocked_id = @user_id;
@@ -0,0 +1,18 @@
+-- name: CreateChirpFlags :exec
+INSERT INTO chirp_flags (chirp_id, word, created_at)
+SELECT @chirp_id::uuid, unnest(@words::text[]), NOW()
+ON CONFLICT (chirp_id, word) DO NOTHING;
+
+-- name: DeleteChirpFlags :exec
+DELETE FROM chirp_flags
+WHERE chirp_id = $1;
+
+-- name: ListFlaggedChirps :many
+SELECT sqlc.embed(chirps), array_agg(chirp_flags.word ORDER BY chirp_flags.word)::text[] AS words, MAX(chirp_flags.created_at)::timestamp AS flagged_at
+FROM chirp_flags
+    JOIN chirps ON chirps.id = chirp_flags.chirp_id
+GROUP BY chirps.id
+HAVING sqlc.narg('cursor_time')::timestamp IS NULL
+    OR (MAX(chirp_flags.created_at), chirps.id) < (sqlc.narg('cursor_time'), sqlc.narg('cursor_id')::uuid)
+ORDER BY flagged_at DESC, chirps.id DESC
+LIMIT @page_size;
@@ -0,0 +1,37 @@
+-- name: CreateChirpHashtags :exec
+INSERT INTO chirp_hashtags (chirp_id, tag, created_at)
+SELECT @chirp_id::uuid, unnest(@tags::text[]), @created_at::timestamp
+ON CONFLICT (chirp_id, tag) DO NOTHING;
+
+-- name: DeleteChirpHashtags :exec
+DELETE FROM chirp_hashtags
+WHERE chirp_id = $1;
+
+-- name: ListHashtagChirps :many
+SELECT chirps.* FROM chirps
+    JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+WHERE chirp_hashtags.tag = @tag
+  AND chirps.hidden_at IS NULL
+  AND (
+    sqlc.narg('cursor_time')::timestamp IS NULL
+    OR (chirps.created_at, chirps.id) < (sqlc.narg('cursor_time'), sqlc.narg('cursor_id')::uuid)
+  )
+  AND chirps.user_id NOT IN (
+    SELECT blocked_id FROM blocks WHERE blocker_id = sqlc.narg('viewer_id')::uuid
+    UNION ALL
+    SELECT blocker_id FROM blocks WHERE blocked_id = sqlc.narg('viewer_id')::uuid
+    UNION ALL
+    SELECT muted_id FROM mutes WHERE muter_id = sqlc.narg('viewer_id')::uuid
+  )
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT @page_size;
+
+-- name: ListTrendingHashtags :many
+SELECT chirp_hashtags.tag, COUNT(*) AS chirp_count
+FROM chirp_hashtags
+    JOIN chirps ON chirps.id = chirp_hashtags.chirp_id
+WHERE chirp_hashtags.created_at > NOW() - (@window_seconds::int * INTERVAL '1 second')
+  AND chirps.hidden_at IS NULL
+GROUP BY chirp_hashtags.tag
+ORDER BY chirp_count DESC, chirp_hashtags.tag
+LIMIT @max_tags;
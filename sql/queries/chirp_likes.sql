@@ -0,0 +1,17 @@
+-- name: CreateChirpLike :exec
+INSERT INTO chirp_likes (chirp_id, user_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (chirp_id, user_id) DO NOTHING;
+
+-- name: DeleteChirpLike :exec
+DELETE FROM chirp_likes
+WHERE chirp_id = $1 AND user_id = $2;
+
+-- name: ListChirpLikeStats :many
+SELECT
+    chirp_id,
+    COUNT(*) AS like_count,
+    COALESCE(BOOL_OR(user_id = sqlc.narg('viewer_id')), false)::boolean AS liked_by_me
+FROM chirp_likes
+WHERE chirp_id = ANY(@chirp_ids::uuid[])
+GROUP BY chirp_id;
@@ -0,0 +1,32 @@
+-- name: CreateChirpMentions :exec
+INSERT INTO chirp_mentions (chirp_id, user_id, start_offset, end_offset, created_at)
+SELECT @chirp_id::uuid, unnest(@user_ids::uuid[]), unnest(@start_offsets::int[]), unnest(@end_offsets::int[]), NOW();
+
+-- name: DeleteChirpMentions :exec
+DELETE FROM chirp_mentions
+WHERE chirp_id = $1;
+
+-- name: ListChirpMentions :many
+SELECT chirp_mentions.chirp_id, chirp_mentions.user_id, users.username, chirp_mentions.start_offset, chirp_mentions.end_offset
+FROM chirp_mentions
+    JOIN users ON users.id = chirp_mentions.user_id
+WHERE chirp_mentions.chirp_id = ANY(@chirp_ids::uuid[])
+ORDER BY chirp_mentions.chirp_id, chirp_mentions.start_offset;
+
+-- name: ListMentionChirps :many
+SELECT * FROM chirps
+WHERE id IN (SELECT chirp_id FROM chirp_mentions WHERE chirp_mentions.user_id = @user_id)
+  AND hidden_at IS NULL
+  AND user_id NOT IN (
+    SELECT blocked_id FROM blocks WHERE blocker_id = @user_id
+    UNION ALL
+    SELECT blocker_id FROM blocks WHERE blocked_id = @user_id
+    UNION ALL
+    SELECT muted_id FROM mutes WHERE muter_id = @user_id
+  )
+  AND (
+    sqlc.narg('cursor_time')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_time'), sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT @page_size;
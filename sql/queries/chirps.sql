@@ -1,21 +1,190 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
-VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to, kind, repost_of, rechirp_of)
+VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2, $3, $4, $5, $6)
 RETURNING *;
 
--- name: GetChirps :many
+-- name: ListChirpsByCreatedAt :many
 SELECT * FROM chirps
-ORDER BY created_at;
+WHERE (COALESCE(cardinality(@author_ids::uuid[]), 0) = 0 OR user_id = ANY(@author_ids::uuid[]))
+  AND (sqlc.narg('since')::timestamp IS NULL OR created_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::timestamp IS NULL OR created_at < sqlc.narg('until'))
+  AND (sqlc.narg('cursor_time')::timestamp IS NULL OR (created_at, id) > (sqlc.narg('cursor_time'), sqlc.narg('cursor_id')::uuid))
+  AND (hidden_at IS NULL OR @include_hidden::boolean OR user_id = sqlc.narg('viewer_id')::uuid)
+  AND user_id NOT IN (
+    SELECT blocked_id FROM blocks WHERE blocker_id = sqlc.narg('viewer_id')::uuid
+    UNION ALL
+    SELECT blocker_id FROM blocks WHERE blocked_id = sqlc.narg('viewer_id')::uuid
+  )
+  AND (
+    COALESCE(cardinality(@author_ids::uuid[]), 0) > 0
+    OR user_id NOT IN (SELECT muted_id FROM mutes WHERE muter_id = sqlc.narg('viewer_id')::uuid)
+  )
+ORDER BY created_at ASC, id ASC
+LIMIT @page_size;
 
--- name: GetChirpsByAuthorId :many
+-- name: ListChirpsByCreatedAtDesc :many
 SELECT * FROM chirps
-WHERE user_id = $1
-ORDER BY created_at;
+WHERE (COALESCE(cardinality(@author_ids::uuid[]), 0) = 0 OR user_id = ANY(@author_ids::uuid[]))
+  AND (sqlc.narg('since')::timestamp IS NULL OR created_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::timestamp IS NULL OR created_at < sqlc.narg('until'))
+  AND (sqlc.narg('cursor_time')::timestamp IS NULL OR (created_at, id) < (sqlc.narg('cursor_time'), sqlc.narg('cursor_id')::uuid))
+  AND (hidden_at IS NULL OR @include_hidden::boolean OR user_id = sqlc.narg('viewer_id')::uuid)
+  AND user_id NOT IN (
+    SELECT blocked_id FROM blocks WHERE blocker_id = sqlc.narg('viewer_id')::uuid
+    UNION ALL
+    SELECT blocker_id FROM blocks WHERE blocked_id = sqlc.narg('viewer_id')::uuid
+  )
+  AND (
+    COALESCE(cardinality(@author_ids::uuid[]), 0) > 0
+    OR user_id NOT IN (SELECT muted_id FROM mutes WHERE muter_id = sqlc.narg('viewer_id')::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT @page_size;
+
+-- name: ListChirpsByUpdatedAt :many
+SELECT * FROM chirps
+WHERE (COALESCE(cardinality(@author_ids::uuid[]), 0) = 0 OR user_id = ANY(@author_ids::uuid[]))
+  AND (sqlc.narg('since')::timestamp IS NULL OR created_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::timestamp IS NULL OR created_at < sqlc.narg('until'))
+  AND (sqlc.narg('cursor_time')::timestamp IS NULL OR (updated_at, id) > (sqlc.narg('cursor_time'), sqlc.narg('cursor_id')::uuid))
+  AND (hidden_at IS NULL OR @include_hidden::boolean OR user_id = sqlc.narg('viewer_id')::uuid)
+  AND user_id NOT IN (
+    SELECT blocked_id FROM blocks WHERE blocker_id = sqlc.narg('viewer_id')::uuid
+    UNION ALL
+    SELECT blocker_id FROM blocks WHERE blocked_id = sqlc.narg('viewer_id')::uuid
+  )
+  AND (
+    COALESCE(cardinality(@author_ids::uuid[]), 0) > 0
+    OR user_id NOT IN (SELECT muted_id FROM mutes WHERE muter_id = sqlc.narg('viewer_id')::uuid)
+  )
+ORDER BY updated_at ASC, id ASC
+LIMIT @page_size;
+
+-- name: ListChirpsByUpdatedAtDesc :many
+SELECT * FROM chirps
+WHERE (COALESCE(cardinality(@author_ids::uuid[]), 0) = 0 OR user_id = ANY(@author_ids::uuid[]))
+  AND (sqlc.narg('since')::timestamp IS NULL OR created_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::timestamp IS NULL OR created_at < sqlc.narg('until'))
+  AND (sqlc.narg('cursor_time')::timestamp IS NULL OR (updated_at, id) < (sqlc.narg('cursor_time'), sqlc.narg('cursor_id')::uuid))
+  AND (hidden_at IS NULL OR @include_hidden::boolean OR user_id = sqlc.narg('viewer_id')::uuid)
+  AND user_id NOT IN (
+    SELECT blocked_id FROM blocks WHERE blocker_id = sqlc.narg('viewer_id')::uuid
+    UNION ALL
+    SELECT blocker_id FROM blocks WHERE blocked_id = sqlc.narg('viewer_id')::uuid
+  )
+  AND (
+    COALESCE(cardinality(@author_ids::uuid[]), 0) > 0
+    OR user_id NOT IN (SELECT muted_id FROM mutes WHERE muter_id = sqlc.narg('viewer_id')::uuid)
+  )
+ORDER BY updated_at DESC, id DESC
+LIMIT @page_size;
 
 -- name: GetChirpById :one
 SELECT * FROM chirps
 WHERE id = $1;
 
+-- name: ListChirpsByIds :many
+SELECT * FROM chirps
+WHERE id = ANY(@ids::uuid[]);
+
 -- name: DeleteChirpById :exec
 DELETE FROM chirps
 WHERE id = $1;
+
+-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = @body, updated_at = NOW(), edited_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: GetChirpForUpdate :one
+SELECT * FROM chirps
+WHERE id = $1
+FOR UPDATE;
+
+-- name: HideChirp :exec
+UPDATE chirps
+SET hidden_at = NOW()
+WHERE id = $1 AND hidden_at IS NULL;
+
+-- name: UnhideChirp :execrows
+UPDATE chirps
+SET hidden_at = NULL
+WHERE id = $1;
+
+-- name: ListChirpAncestors :many
+WITH RECURSIVE ancestors (id, depth) AS (
+    SELECT parent.id, 1
+    FROM chirps child
+        JOIN chirps parent ON parent.id = child.in_reply_to
+    WHERE child.id = @chirp_id
+    UNION ALL
+    SELECT parent.id, ancestors.depth + 1
+    FROM ancestors
+        JOIN chirps child ON child.id = ancestors.id
+        JOIN chirps parent ON parent.id = child.in_reply_to
+    WHERE ancestors.depth < @max_depth::int
+)
+SELECT sqlc.embed(chirps), ancestors.depth
+FROM ancestors
+    JOIN chirps ON chirps.id = ancestors.id
+ORDER BY ancestors.depth DESC;
+
+-- name: ListChirpReplies :many
+WITH RECURSIVE replies (id, depth) AS (
+    SELECT chirps.id, 1
+    FROM chirps
+    WHERE chirps.in_reply_to = @chirp_id
+    UNION ALL
+    SELECT chirps.id, replies.depth + 1
+    FROM replies
+        JOIN chirps ON chirps.in_reply_to = replies.id
+    WHERE replies.depth < @max_depth::int
+)
+SELECT sqlc.embed(chirps), replies.depth
+FROM replies
+    JOIN chirps ON chirps.id = replies.id
+ORDER BY replies.depth, chirps.created_at, chirps.id
+LIMIT @max_replies;
+
+-- name: ListTimelineChirps :many
+SELECT * FROM chirps
+WHERE (
+    user_id = @user_id
+    OR user_id IN (SELECT followee_id FROM follows WHERE follower_id = @user_id)
+  )
+  AND (hidden_at IS NULL OR user_id = @user_id)
+  AND user_id NOT IN (
+    SELECT blocked_id FROM blocks WHERE blocker_id = @user_id
+    UNION ALL
+    SELECT blocker_id FROM blocks WHERE blocked_id = @user_id
+  )
+  AND user_id NOT IN (SELECT muted_id FROM mutes WHERE muter_id = @user_id)
+  AND (
+    sqlc.narg('cursor_time')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_time'), sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT @page_size;
+
+-- name: SearchChirps :many
+SELECT sqlc.embed(chirps), results.rank, ts_headline('english', chirps.body, results.query, @headline_options::text) AS headline
+FROM (
+    SELECT chirps.id, ts_rank(chirps.search_vector, query) AS rank, query
+    FROM chirps, websearch_to_tsquery('english', @query::text) query
+    WHERE chirps.search_vector @@ query AND chirps.hidden_at IS NULL
+) results
+    JOIN chirps ON chirps.id = results.id
+WHERE (
+    sqlc.narg('cursor_rank')::real IS NULL
+    OR (results.rank, chirps.id) < (sqlc.narg('cursor_rank')::real, sqlc.narg('cursor_id')::uuid)
+  )
+  AND chirps.user_id NOT IN (
+    SELECT blocked_id FROM blocks WHERE blocker_id = sqlc.narg('viewer_id')::uuid
+    UNION ALL
+    SELECT blocker_id FROM blocks WHERE blocked_id = sqlc.narg('viewer_id')::uuid
+    UNION ALL
+    SELECT muted_id FROM mutes WHERE muter_id = sqlc.narg('viewer_id')::uuid
+  )
+ORDER BY results.rank DESC, chirps.id DESC
+LIMIT @page_size;
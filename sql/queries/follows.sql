@@ -0,0 +1,33 @@
+-- name: CreateFollow :exec
+INSERT INTO follows (follower_id, followee_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (follower_id, followee_id) DO NOTHING;
+
+-- name: DeleteFollow :exec
+DELETE FROM follows
+WHERE follower_id = $1 AND followee_id = $2;
+
+-- name: ListFollowers :many
+SELECT follower_id AS user_id, created_at FROM follows
+WHERE followee_id = @user_id
+  AND (
+    sqlc.narg('cursor_time')::timestamp IS NULL
+    OR (created_at, follower_id) < (sqlc.narg('cursor_time'), sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, follower_id DESC
+LIMIT @page_size;
+
+-- name: ListFollowing :many
+SELECT followee_id AS user_id, created_at FROM follows
+WHERE follower_id = @user_id
+  AND (
+    sqlc.narg('cursor_time')::timestamp IS NULL
+    OR (created_at, followee_id) < (sqlc.narg('cursor_time'), sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, followee_id DESC
+LIMIT @page_size;
+
+-- name: DeleteFollowsBetween :exec
+DELETE FROM follows
+WHERE (follower_id = @user_id AND followee_id = @other_user_id)
+   OR (follower_id = @other_user_id AND followee_id = @user_id);
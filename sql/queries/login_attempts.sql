@@ -0,0 +1,21 @@
+-- name: CreateLoginAttempt :exec
+INSERT INTO login_attempts (id, user_id, email, ip, success, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, NOW());
+
+-- name: GetAccountLoginFailures :one
+SELECT COUNT(*) AS failures, COALESCE(MAX(created_at), 'epoch')::timestamp AS last_failure, NOW()::timestamp AS now
+FROM login_attempts
+WHERE email = @email
+  AND NOT success
+  AND created_at > NOW() - (@window_seconds::int * INTERVAL '1 second')
+  AND created_at > COALESCE(
+    (SELECT MAX(successes.created_at) FROM login_attempts successes WHERE successes.email = @email AND successes.success),
+    'epoch'
+  );
+
+-- name: GetIPLoginFailures :one
+SELECT COUNT(*) AS failures, COALESCE(MAX(created_at), 'epoch')::timestamp AS last_failure, NOW()::timestamp AS now
+FROM login_attempts
+WHERE ip = @ip
+  AND NOT success
+  AND created_at > NOW() - (@window_seconds::int * INTERVAL '1 second');
@@ -0,0 +1,13 @@
+-- name: DeleteModerationRule :exec
+DELETE FROM moderation_rules
+WHERE word = $1;
+
+-- name: ListModerationRules :many
+SELECT * FROM moderation_rules
+ORDER BY word;
+
+-- name: UpsertModerationRule :one
+INSERT INTO moderation_rules (word, action, created_at, updated_at)
+VALUES ($1, $2, NOW(), NOW())
+ON CONFLICT (word) DO UPDATE SET action = EXCLUDED.action, updated_at = NOW()
+RETURNING *;
@@ -0,0 +1,25 @@
+-- name: CreatePersonalAccessToken :one
+INSERT INTO personal_access_tokens (id, user_id, name, token_hash, scopes, created_at, expires_at)
+VALUES (gen_random_uuid(), @user_id, @name, @token_hash, @scopes::text[], NOW(), sqlc.narg('expires_at'))
+RETURNING *;
+
+-- name: ListPersonalAccessTokens :many
+SELECT * FROM personal_access_tokens
+WHERE user_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at DESC;
+
+-- name: RevokePersonalAccessToken :execrows
+UPDATE personal_access_tokens
+SET revoked_at = NOW()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: UsePersonalAccessToken :one
+UPDATE personal_access_tokens
+SET last_used_at = NOW()
+FROM users
+WHERE personal_access_tokens.token_hash = $1
+    AND personal_access_tokens.revoked_at IS NULL
+    AND (personal_access_tokens.expires_at IS NULL OR personal_access_tokens.expires_at > NOW())
+    AND users.id = personal_access_tokens.user_id
+    AND users.suspended_at IS NULL
+RETURNING personal_access_tokens.*;
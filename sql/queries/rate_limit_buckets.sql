@@ -0,0 +1,16 @@
+-- name: DeleteStaleRateLimitBuckets :exec
+DELETE FROM rate_limit_buckets
+WHERE updated_at < NOW() - (@max_age_seconds::int * INTERVAL '1 second');
+
+-- name: TakeRateLimitToken :one
+INSERT INTO rate_limit_buckets AS buckets (key, tokens, allowed, updated_at)
+VALUES (@key, @capacity::float8 - 1, TRUE, NOW())
+ON CONFLICT (key) DO UPDATE SET
+    tokens = CASE
+        WHEN LEAST(@capacity::float8, buckets.tokens + EXTRACT(EPOCH FROM NOW() - buckets.updated_at)::float8 * @refill_rate::float8) >= 1
+        THEN LEAST(@capacity::float8, buckets.tokens + EXTRACT(EPOCH FROM NOW() - buckets.updated_at)::float8 * @refill_rate::float8) - 1
+        ELSE LEAST(@capacity::float8, buckets.tokens + EXTRACT(EPOCH FROM NOW() - buckets.updated_at)::float8 * @refill_rate::float8)
+    END,
+    allowed = LEAST(@capacity::float8, buckets.tokens + EXTRACT(EPOCH FROM NOW() - buckets.updated_at)::float8 * @refill_rate::float8) >= 1,
+    updated_at = NOW()
+RETURNING tokens, allowed;
@@ -0,0 +1,34 @@
+-- name: CreateReport :one
+INSERT INTO reports (id, reporter_id, chirp_id, user_id, reason, status, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, 'open', NOW())
+RETURNING *;
+
+-- name: GetReportById :one
+SELECT * FROM reports
+WHERE id = $1;
+
+-- name: ListReports :many
+SELECT * FROM reports
+WHERE status = @status
+  AND (
+    sqlc.narg('cursor_time')::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg('cursor_time'), sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at, id
+LIMIT @page_size;
+
+-- name: ResolveChirpReports :exec
+UPDATE reports
+SET status = $1, resolved_at = NOW(), resolved_by = $2
+WHERE chirp_id = $3 AND status = 'open';
+
+-- name: ResolveReport :one
+UPDATE reports
+SET status = $1, resolved_at = NOW(), resolved_by = $2
+WHERE id = $3 AND status = 'open'
+RETURNING *;
+
+-- name: ResolveUserReports :exec
+UPDATE reports
+SET status = $1, resolved_at = NOW(), resolved_by = $2
+WHERE user_id = $3 AND status = 'open';
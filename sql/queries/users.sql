@@ -1,21 +1,92 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password, is_chirpy_red)
-VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2, false)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, is_chirpy_red, username)
+VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2, false, $3)
 RETURNING *;
 
 -- name: DeleteAllUsers :exec
 DELETE FROM users;
 
+-- name: GetTokensValidAfter :one
+SELECT tokens_valid_after FROM users WHERE id = $1;
+
 -- name: GetUserByEmail :one
 SELECT * FROM users WHERE email = $1;
 
+-- name: GetUserById :one
+SELECT * FROM users WHERE id = $1;
+
+-- name: GetUserByUsername :one
+SELECT * FROM users WHERE lower(username) = lower(@username);
+
+-- name: ListUsersByIds :many
+SELECT * FROM users WHERE id = ANY(@ids::uuid[]);
+
+-- name: ListUsersByUsernames :many
+SELECT * FROM users WHERE lower(username) = ANY(@usernames::text[]);
+
+-- name: RevokeAccessTokens :exec
+UPDATE users
+SET tokens_valid_after = NOW(), updated_at = NOW()
+WHERE id = $1;
+
+-- name: SuspendUser :exec
+UPDATE users
+SET suspended_at = NOW(), tokens_valid_after = NOW(), updated_at = NOW()
+WHERE id = $1 AND suspended_at IS NULL;
+
+-- name: UnsuspendUser :one
+UPDATE users
+SET suspended_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
 -- name: UpdateUser :one
 UPDATE users
-SET email = $1, hashed_password = $2, updated_at = NOW()
-WHERE id = $3
+SET email = @email,
+    hashed_password = @hashed_password,
+    username = COALESCE(sqlc.narg('username')::text, username),
+    display_name = COALESCE(sqlc.narg('display_name')::text, display_name),
+    bio = COALESCE(sqlc.narg('bio')::text, bio),
+    updated_at = NOW()
+WHERE id = @id
 RETURNING *;
 
 -- name: UpdateChirpyRedStatus :exec
 UPDATE users
 SET is_chirpy_red = $1, updated_at = NOW()
 WHERE id = $2;
+
+-- name: UpdateUserEmail :one
+UPDATE users
+SET email = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET hashed_password = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: UpdateUserProfile :one
+UPDATE users
+SET username = COALESCE(sqlc.narg('username')::text, username),
+    display_name = COALESCE(sqlc.narg('display_name')::text, display_name),
+    bio = COALESCE(sqlc.narg('bio')::text, bio),
+    updated_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $1,
+    updated_at = NOW(),
+    tokens_valid_after = CASE WHEN role <> $1 THEN NOW() ELSE tokens_valid_after END
+WHERE id = $2
+RETURNING *;
+
+-- name: BootstrapAdmin :execrows
+UPDATE users
+SET role = 'admin', updated_at = NOW()
+WHERE email = $1
+  AND NOT EXISTS (SELECT 1 FROM users WHERE role = 'admin');